@@ -0,0 +1,421 @@
+package hclbuilder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// BlockBuilder provides a fluent API for modifying a single block of a Builder.
+// It shares the underlying HCL with the Builder it was obtained from, so every
+// mutation is visible in that Builder (and in any builder it was upserted into).
+type BlockBuilder struct {
+	builder *Builder
+	block   *hclwrite.Block
+	path    string
+	// err is set when the path the BlockBuilder was obtained with is invalid.
+	err error
+}
+
+// NestedBlock describes a nested block in the attributes passed to SetBlock,
+// the map key being the block type. Use a []NestedBlock value for repeated
+// blocks of the same type.
+//
+// Example:
+//
+//	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{
+//	    "name": "mesh-1",
+//	    "lifecycle": hclbuilder.NestedBlock{
+//	        Attributes: map[string]any{"prevent_destroy": true},
+//	    },
+//	    "provisioner": []hclbuilder.NestedBlock{
+//	        {Labels: []string{"local-exec"}, Attributes: map[string]any{"command": "echo created"}},
+//	        {Labels: []string{"local-exec"}, Attributes: map[string]any{"command": "echo destroyed", "when": "destroy"}},
+//	    },
+//	})
+type NestedBlock struct {
+	// Labels of the nested block, e.g. []string{"local-exec"} for a provisioner.
+	Labels []string
+	// Attributes of the nested block. NestedBlock values are nested blocks themselves.
+	Attributes map[string]any
+}
+
+// Block returns a BlockBuilder for the block at the given path, so that
+// mutations can be aimed at a specific resource, data source, variable or
+// module in a multi-block file.
+//
+// Path format: "block_type.block_label1.block_label2...". Labels containing
+// dots must be quoted (see JoinPath).
+// Example: "resource.kong-mesh_mesh.default", "data.konnect_mesh_control_plane.cp", `provider."kong-mesh.eu"`.
+//
+// If no block matches the path, the returned BlockBuilder does nothing and
+// its mutating methods record ErrBlockNotFound (see Builder.Err), or
+// ErrInvalidPath if the path is invalid.
+func (b *Builder) Block(path string) *BlockBuilder {
+	bb := &BlockBuilder{builder: b, path: path}
+	parts, err := splitBlockPath(path)
+	if err != nil {
+		bb.err = err
+		return bb
+	}
+	bb.block = findBlock(b.file.Body(), parts[0], parts[1:])
+	return bb
+}
+
+// firstBlock returns a BlockBuilder for the first block in this builder.
+func (b *Builder) firstBlock() *BlockBuilder {
+	bb := &BlockBuilder{builder: b}
+	if blocks := b.file.Body().Blocks(); len(blocks) > 0 {
+		bb.block = blocks[0]
+		bb.path = blockPath(bb.block)
+	}
+	return bb
+}
+
+// blockPath returns the path of a block in the format accepted by Block.
+func blockPath(block *hclwrite.Block) string {
+	return JoinPath(append([]string{block.Type()}, block.Labels()...)...)
+}
+
+// addError records a failed operation on the builder this block belongs to.
+func (bb *BlockBuilder) addError(op, path string, err error) {
+	switch {
+	case path == "":
+		path = bb.path
+	case bb.path != "":
+		path = bb.path + "." + path
+	}
+	bb.builder.addError(op, path, err)
+}
+
+// notFound records ErrBlockNotFound for an operation on a missing block, or
+// the error of the path the block was looked up with.
+func (bb *BlockBuilder) notFound(op, path string) {
+	if bb.err != nil {
+		bb.addError(op, path, bb.err)
+		return
+	}
+	if bb.path == "" {
+		bb.builder.addError(op, path, fmt.Errorf("%w: builder has no blocks", ErrBlockNotFound))
+		return
+	}
+	bb.addError(op, path, ErrBlockNotFound)
+}
+
+// Block returns a BlockBuilder for the nested block at the given path,
+// relative to this block.
+//
+// Path format: "block_type.block_label1...".
+// Example: "lifecycle", "dynamic.ingress".
+func (bb *BlockBuilder) Block(path string) *BlockBuilder {
+	nested := &BlockBuilder{
+		builder: bb.builder,
+		path:    path,
+	}
+	if bb.path != "" {
+		nested.path = bb.path + "." + path
+	}
+
+	parts, err := splitBlockPath(path)
+	switch {
+	case bb.err != nil:
+		nested.err = bb.err
+	case err != nil:
+		nested.err = err
+	case bb.block != nil:
+		nested.block = findBlock(bb.block.Body(), parts[0], parts[1:])
+	}
+	return nested
+}
+
+// SetBlock creates or replaces the nested block at the given path, relative
+// to this block, with the given attributes. An existing block is replaced in
+// place, and keeps the comments above it.
+//
+// Path format: "block_type.block_label1...".
+// Example: "lifecycle", "timeouts", "dynamic.ingress".
+func (bb *BlockBuilder) SetBlock(path string, attributes map[string]any) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("SetBlock", path)
+		return bb
+	}
+
+	parts, err := splitBlockPath(path)
+	if err != nil {
+		bb.addError("SetBlock", path, err)
+		return bb
+	}
+	block, err := newBlock(parts[0], NestedBlock{Labels: parts[1:], Attributes: attributes})
+	if err != nil {
+		bb.addError("SetBlock", path, err)
+		return bb
+	}
+
+	body := bb.block.Body()
+	if existing := findBlock(body, parts[0], parts[1:]); existing != nil {
+		replaceBlock(body, existing, block)
+	} else {
+		body.AppendBlock(block)
+	}
+
+	return bb
+}
+
+// AddBlock appends a nested block of the given type to this block, even if
+// blocks of the same type already exist (e.g. several provisioners).
+func (bb *BlockBuilder) AddBlock(blockType string, nested NestedBlock) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("AddBlock", blockType)
+		return bb
+	}
+
+	block, err := newBlock(blockType, nested)
+	if err != nil {
+		bb.addError("AddBlock", blockType, err)
+		return bb
+	}
+	bb.block.Body().AppendBlock(block)
+
+	return bb
+}
+
+// RemoveBlock removes the nested block at the given path, relative to this block.
+func (bb *BlockBuilder) RemoveBlock(path string) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("RemoveBlock", path)
+		return bb
+	}
+
+	parts, err := splitBlockPath(path)
+	if err != nil {
+		bb.addError("RemoveBlock", path, err)
+		return bb
+	}
+	if !removeBlock(bb.block.Body(), parts[0], parts[1:]) {
+		bb.addError("RemoveBlock", path, ErrBlockNotFound)
+	}
+
+	return bb
+}
+
+// Exists reports whether the block this BlockBuilder refers to exists.
+func (bb *BlockBuilder) Exists() bool {
+	return bb.block != nil
+}
+
+// AddAttribute adds or updates an attribute on this block.
+// The path uses dot notation for nested attributes, and "[n]" or "[*]" to
+// address one or every element of a list.
+// Value can be a Go value or a string containing HCL expression.
+// Example: builder.Block("resource.kong-mesh_mesh.default").AddAttribute("routing.default_forbid_mesh_external_service_access", "true")
+// Example: builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").AddAttribute("spec.from[*].default.action", `"Deny"`)
+func (bb *BlockBuilder) AddAttribute(path string, value any) *BlockBuilder {
+	return bb.editAttribute("AddAttribute", path, value, func(tokens hclwrite.Tokens, steps []pathStep) ([]pathStep, editFunc, error) {
+		return steps, replaceWith(tokens.Bytes()), nil
+	})
+}
+
+// AppendAttribute appends a value to the list at path, creating the list if
+// it does not exist.
+// Example: builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").AppendAttribute("spec.from[0].target_ref.proxy_types", "Sidecar")
+func (bb *BlockBuilder) AppendAttribute(path string, value any) *BlockBuilder {
+	return bb.editAttribute("AppendAttribute", path, value, func(tokens hclwrite.Tokens, steps []pathStep) ([]pathStep, editFunc, error) {
+		return steps, insertElement(-1, tokens.Bytes()), nil
+	})
+}
+
+// InsertAttribute inserts a value into a list before the element at the index
+// path ends with. An index equal to the length of the list appends the value.
+// Example: builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").InsertAttribute("spec.from[0]", map[string]any{"target_ref": map[string]any{"kind": "Mesh"}})
+func (bb *BlockBuilder) InsertAttribute(path string, value any) *BlockBuilder {
+	return bb.editAttribute("InsertAttribute", path, value, func(tokens hclwrite.Tokens, steps []pathStep) ([]pathStep, editFunc, error) {
+		last := steps[len(steps)-1]
+		if !last.isIndex {
+			return nil, nil, fmt.Errorf("%w: path must end with a list index", ErrInvalidPath)
+		}
+		return steps[:len(steps)-1], insertElement(last.index, tokens.Bytes()), nil
+	})
+}
+
+// editAttribute applies an edit to the attribute at path. prepare receives
+// the value tokens and the parsed path, and returns the path of the
+// expression to edit and the edit to apply to it.
+func (bb *BlockBuilder) editAttribute(
+	op, path string,
+	value any,
+	prepare func(tokens hclwrite.Tokens, steps []pathStep) ([]pathStep, editFunc, error),
+) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound(op, path)
+		return bb
+	}
+
+	steps, err := parseAttributePath(path)
+	if err != nil {
+		bb.addError(op, path, err)
+		return bb
+	}
+
+	tokens, err := attributeValueTokens(value)
+	if err != nil {
+		bb.addError(op, path, err)
+		return bb
+	}
+
+	steps, edit, err := prepare(tokens, steps)
+	if err != nil {
+		bb.addError(op, path, err)
+		return bb
+	}
+
+	// Edit the existing expression, keeping everything that is not on the path untouched
+	body := bb.block.Body()
+	rootAttr := steps[0].key
+	var src []byte
+	if attr := body.GetAttribute(rootAttr); attr != nil {
+		src = exprSource(attr)
+	}
+
+	if src, err = editNested(src, steps[1:], edit); err != nil {
+		bb.addError(op, path, err)
+		return bb
+	}
+	if tokens, err = exprTokens(src); err != nil {
+		bb.addError(op, path, err)
+		return bb
+	}
+	body.SetAttributeRaw(rootAttr, tokens)
+
+	return bb
+}
+
+// attributeValueTokens returns the tokens for an attribute value. Strings that
+// are valid HCL expressions are interpreted as HCL (a literal such as `["*"]`
+// or a reference such as `kong-mesh_mesh.default.name`), Expressions are
+// written as-is and other values are converted to literals. Use Literal to
+// write a string that must not be interpreted.
+func attributeValueTokens(value any) (hclwrite.Tokens, error) {
+	if strValue, ok := value.(string); ok {
+		if parsedValue := parseHCLValue(strValue); parsedValue != nil {
+			value = parsedValue
+		} else if isHCLExpression(strValue) {
+			// It's a valid HCL expression but can't be evaluated (e.g., a reference)
+			// Keep it as-is
+			return exprTokens([]byte(strValue))
+		}
+	}
+	return valueTokens(value)
+}
+
+// RemoveAttribute removes an attribute from this block.
+// Uses dot notation for nested attributes, and "[n]" or "[*]" for list elements.
+// Example: builder.Block("resource.kong-mesh_mesh.default").RemoveAttribute("routing.default_forbid_mesh_external_service_access")
+// will remove only the nested field, leaving other fields in "routing" intact.
+func (bb *BlockBuilder) RemoveAttribute(path string) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("RemoveAttribute", path)
+		return bb
+	}
+
+	steps, err := parseAttributePath(path)
+	if err != nil {
+		bb.addError("RemoveAttribute", path, err)
+		return bb
+	}
+
+	block := bb.block
+	if len(steps) == 1 {
+		// Simple attribute
+		if block.Body().RemoveAttribute(steps[0].key) == nil {
+			bb.addError("RemoveAttribute", path, ErrAttributeNotFound)
+		}
+		return bb
+	}
+
+	// Nested attribute - edit the existing expression and write it back
+	rootAttr := steps[0].key
+
+	// Get existing value if present
+	attr := block.Body().GetAttribute(rootAttr)
+	if attr == nil {
+		// Attribute doesn't exist, nothing to remove
+		bb.addError("RemoveAttribute", path, ErrAttributeNotFound)
+		return bb
+	}
+
+	// Navigate to the nested structure and remove the specific field
+	src, found, empty, err := removeNested(exprSource(attr), steps[1:])
+	if err != nil {
+		bb.addError("RemoveAttribute", path, err)
+		return bb
+	}
+	if !found {
+		bb.addError("RemoveAttribute", path, ErrAttributeNotFound)
+		return bb
+	}
+	if empty {
+		// The entire structure was removed
+		block.Body().RemoveAttribute(rootAttr)
+		return bb
+	}
+
+	// Update with the modified structure
+	tokens, err := exprTokens(src)
+	if err != nil {
+		bb.addError("RemoveAttribute", path, err)
+		return bb
+	}
+	block.Body().SetAttributeRaw(rootAttr, tokens)
+
+	return bb
+}
+
+// ResourceName returns the name of this block: the second label for blocks
+// with two labels (resource "type" "name") and the only label otherwise.
+// Returns empty string if the block does not exist or has no labels.
+func (bb *BlockBuilder) ResourceName() string {
+	if bb.block == nil {
+		return ""
+	}
+
+	labels := bb.block.Labels()
+	if len(labels) == 0 {
+		return ""
+	}
+
+	// For resources, the first label is the resource type, second is the name
+	// e.g., resource "konnect_mesh_control_plane" "my_meshcontrolplane"
+	// labels[0] = "konnect_mesh_control_plane", labels[1] = "my_meshcontrolplane"
+	if len(labels) >= 2 {
+		return labels[1]
+	}
+
+	return labels[0]
+}
+
+// ResourcePath returns the full Terraform resource path (e.g., "konnect_mesh_control_plane.my_meshcontrolplane")
+// of this block. Returns empty string if the block does not exist or is not a resource.
+func (bb *BlockBuilder) ResourcePath() string {
+	if bb.block == nil {
+		return ""
+	}
+
+	// Only process resource blocks
+	if bb.block.Type() != "resource" {
+		return ""
+	}
+
+	labels := bb.block.Labels()
+	if len(labels) < 2 {
+		return ""
+	}
+
+	// For resources: resource "type" "name" -> type.name
+	// e.g., resource "konnect_mesh_control_plane" "cp1" -> konnect_mesh_control_plane.cp1
+	return fmt.Sprintf("%s.%s", labels[0], labels[1])
+}
@@ -0,0 +1,145 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test Block() - mutate a specific block in a multi-block file
+func TestBlock_MultiBlock(t *testing.T) {
+	inputFile := filepath.Join("testdata", "block-multi.input.tf")
+	builder, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	mesh := builder.Block("resource.kong-mesh_mesh.default")
+	policy := builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all")
+
+	mesh.AddAttribute("routing.default_forbid_mesh_external_service_access", true).
+		RemoveAttribute("skip_creating_initial_policies")
+	policy.AddAttribute("mesh", "kong-mesh_mesh.default.name")
+	builder.Block("variable.mesh_name").AddAttribute("description", "Name of the mesh")
+
+	result := builder.Build()
+	goldenFile := filepath.Join("testdata", "block-multi.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test Block() - resource name and path of a specific block
+func TestBlock_ResourcePath(t *testing.T) {
+	inputFile := filepath.Join("testdata", "block-multi.input.tf")
+	builder, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	// The first block is the provider, so the builder-level helpers find nothing
+	require.Empty(t, builder.ResourcePath())
+
+	policy := builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all")
+	require.True(t, policy.Exists())
+	require.Equal(t, "allow_all", policy.ResourceName())
+	require.Equal(t, "kong-mesh_mesh_traffic_permission.allow_all", policy.ResourcePath())
+
+	variable := builder.Block("variable.mesh_name")
+	require.Equal(t, "mesh_name", variable.ResourceName())
+	require.Empty(t, variable.ResourcePath())
+}
+
+// Test Block() - depends_on on a specific block
+func TestBlock_DependsOn(t *testing.T) {
+	inputFile := filepath.Join("testdata", "block-multi.input.tf")
+	builder, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	mesh, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh" "default" {
+  name = "default"
+}
+`)
+	require.NoError(t, err)
+
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").DependsOn(mesh)
+
+	result := builder.Build()
+	goldenFile := filepath.Join("testdata", "block-depends-on.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test Block() - missing block is a no-op
+func TestBlock_Missing(t *testing.T) {
+	inputFile := filepath.Join("testdata", "block-multi.input.tf")
+	builder, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+	before := builder.Build()
+
+	missing := builder.Block("resource.kong-mesh_mesh.other")
+	require.False(t, missing.Exists())
+	missing.AddAttribute("name", "other").RemoveAttribute("type")
+
+	require.Equal(t, before, builder.Build())
+	require.Empty(t, missing.ResourceName())
+	require.Empty(t, missing.ResourcePath())
+}
+
+// Test SetBlock() - nested blocks given as NestedBlock values
+func TestSetBlock_NestedBlocks(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{
+		"name": "mesh-1",
+		"type": "Mesh",
+		"lifecycle": hclbuilder.NestedBlock{
+			Attributes: map[string]any{
+				"prevent_destroy": true,
+			},
+		},
+		"timeouts": hclbuilder.NestedBlock{},
+		"dynamic": hclbuilder.NestedBlock{
+			Labels: []string{"backend"},
+			Attributes: map[string]any{
+				"for_each": []string{"builtin"},
+				"content": hclbuilder.NestedBlock{
+					Attributes: map[string]any{
+						"type": "builtin",
+					},
+				},
+			},
+		},
+		"provisioner": []hclbuilder.NestedBlock{
+			{Labels: []string{"local-exec"}, Attributes: map[string]any{"command": "echo created"}},
+			{Labels: []string{"local-exec"}, Attributes: map[string]any{"command": "echo destroyed", "when": "destroy"}},
+		},
+	})
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "set-block-nested-blocks.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test BlockBuilder nested block methods
+func TestBlock_NestedBlocks(t *testing.T) {
+	inputFile := filepath.Join("testdata", "block-multi.input.tf")
+	builder, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	mesh := builder.Block("resource.kong-mesh_mesh.default")
+	mesh.SetBlock("lifecycle", map[string]any{"create_before_destroy": true}).
+		SetBlock("timeouts", map[string]any{"create": "5m"}).
+		AddBlock("provisioner", hclbuilder.NestedBlock{Labels: []string{"local-exec"}, Attributes: map[string]any{"command": "echo 1"}}).
+		AddBlock("provisioner", hclbuilder.NestedBlock{Labels: []string{"local-exec"}, Attributes: map[string]any{"command": "echo 2"}}).
+		RemoveBlock("timeouts")
+	mesh.Block("lifecycle").AddAttribute("prevent_destroy", true)
+
+	require.True(t, mesh.Block("provisioner.local-exec").Exists())
+	require.False(t, mesh.Block("timeouts").Exists())
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "block-nested-blocks.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	mesh.Block("timeouts").AddAttribute("create", "5m")
+	requirePathError(t, builder.Err(), "AddAttribute", "resource.kong-mesh_mesh.default.timeouts.create", hclbuilder.ErrBlockNotFound)
+}
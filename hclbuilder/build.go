@@ -0,0 +1,151 @@
+package hclbuilder
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// BuildOptions control how BuildWith renders the configuration, so that
+// generated files are stable whatever the order of the operations that
+// produced them.
+type BuildOptions struct {
+	// Format formats the output as terraform fmt does: indentation, spacing
+	// and equals signs aligned across consecutive attributes.
+	Format bool
+	// SortAttributes orders the content of every block canonically, following
+	// the Terraform style guide: the count, for_each and provider
+	// meta-arguments first, then the other attributes sorted by name, nested
+	// blocks in their order, and depends_on and lifecycle last. Comments stay
+	// above the item they describe, other blank lines are dropped.
+	SortAttributes bool
+	// TrailingNewline ends a non-empty output with exactly one newline.
+	TrailingNewline bool
+}
+
+// leadingMetaArguments are the meta-arguments written first in resource,
+// data, ephemeral and module blocks, in this order.
+var leadingMetaArguments = []string{"count", "for_each", "provider"}
+
+// BuildWith returns the HCL configuration rendered according to opts,
+// together with Err (see BuildE). The builder itself is left untouched.
+// Example: for golden files matching terraform fmt output,
+//
+//	result, err := builder.BuildWith(hclbuilder.BuildOptions{Format: true, SortAttributes: true, TrailingNewline: true})
+func (b *Builder) BuildWith(opts BuildOptions) (string, error) {
+	file := b.file
+	if opts.SortAttributes {
+		file = b.Clone().file
+		for _, block := range file.Body().Blocks() {
+			switch block.Type() {
+			case "resource", "data", "ephemeral", "module":
+				sortBody(block.Body(), true)
+			default:
+				sortBody(block.Body(), false)
+			}
+		}
+	}
+
+	out := file.Bytes()
+	if opts.Format {
+		out = hclwrite.Format(out)
+	}
+	if opts.TrailingNewline && len(bytes.TrimSpace(out)) > 0 {
+		out = append(bytes.TrimRight(out, "\n"), '\n')
+	}
+	return string(out), b.Err()
+}
+
+// sortBody orders the items of body canonically (see BuildOptions), and the
+// items of its nested blocks. Meta-arguments are only placed first and last
+// in the bodies of resources, data sources and modules.
+func sortBody(body *hclwrite.Body, meta bool) {
+	type sortedItem struct {
+		bodyItem
+		comments hclwrite.Tokens
+	}
+
+	var leading, attributes, blocks, trailing []sortedItem
+	var comments hclwrite.Tokens
+	for _, item := range bodyItems(body) {
+		if item.block == nil && item.attribute == nil {
+			// Standalone comments go with the next item, blank lines are dropped
+			comments = append(comments, commentTokens(item.tokens)...)
+			continue
+		}
+		sorted := sortedItem{bodyItem: item, comments: comments}
+		comments = nil
+
+		switch {
+		case item.block != nil:
+			sortBody(item.block.Body(), false)
+			if meta && item.block.Type() == "lifecycle" {
+				trailing = append(trailing, sorted)
+			} else {
+				blocks = append(blocks, sorted)
+			}
+		case meta && slices.Contains(leadingMetaArguments, item.name):
+			leading = append(leading, sorted)
+		case meta && item.name == "depends_on":
+			trailing = append(trailing, sorted)
+		default:
+			attributes = append(attributes, sorted)
+		}
+	}
+
+	sort.SliceStable(leading, func(i, j int) bool {
+		return slices.Index(leadingMetaArguments, leading[i].name) < slices.Index(leadingMetaArguments, leading[j].name)
+	})
+	sort.SliceStable(attributes, func(i, j int) bool {
+		return attributes[i].name < attributes[j].name
+	})
+	sort.SliceStable(trailing, func(i, j int) bool {
+		// depends_on goes before lifecycle
+		return trailing[i].attribute != nil && trailing[j].attribute == nil
+	})
+
+	rebuildBody(body, func(bodyItem) {})
+	if len(leading)+len(attributes)+len(blocks)+len(trailing) > 0 {
+		// Items start on the line after the opening brace, also for single-line blocks
+		body.AppendNewline()
+	}
+	written := false
+	for _, group := range [][]sortedItem{leading, append(attributes, blocks...), trailing} {
+		if len(group) == 0 {
+			continue
+		}
+		// Meta-arguments are set apart by a blank line
+		if written {
+			body.AppendNewline()
+		}
+		for _, item := range group {
+			if len(item.comments) > 0 {
+				body.AppendUnstructuredTokens(item.comments)
+			}
+			appendItem(body, item.bodyItem)
+		}
+		written = true
+	}
+	if len(comments) > 0 {
+		body.AppendUnstructuredTokens(comments)
+	}
+}
+
+// commentTokens returns the comments among tokens, each one ending with a
+// newline.
+func commentTokens(tokens hclwrite.Tokens) hclwrite.Tokens {
+	var comments hclwrite.Tokens
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			continue
+		}
+		comments = append(comments, token)
+		if !bytes.HasSuffix(token.Bytes, []byte("\n")) {
+			comments = append(comments, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+		}
+	}
+	return comments
+}
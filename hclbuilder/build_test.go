@@ -0,0 +1,52 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test BuildWith() - canonical output
+func TestBuildWith(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "build-options.input.tf"))
+	require.NoError(t, err)
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").AddAttribute("description", `"Allow all traffic"`)
+	before := builder.Build()
+
+	result, err := builder.BuildWith(hclbuilder.BuildOptions{Format: true, SortAttributes: true, TrailingNewline: true})
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "build-options.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	// The builder is left untouched
+	require.Equal(t, before, builder.Build())
+
+	// Rendering canonical output again gives the same result
+	canonical, err := hclbuilder.FromString(result)
+	require.NoError(t, err)
+	again, err := canonical.BuildWith(hclbuilder.BuildOptions{Format: true, SortAttributes: true, TrailingNewline: true})
+	require.NoError(t, err)
+	require.Equal(t, result, again)
+}
+
+// Test BuildWith() - without options, the output is the one of Build
+func TestBuildWith_NoOptions(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "build-options.input.tf"))
+	require.NoError(t, err)
+
+	result, err := builder.BuildWith(hclbuilder.BuildOptions{})
+	require.NoError(t, err)
+	require.Equal(t, builder.Build(), result)
+
+	result, err = builder.BuildWith(hclbuilder.BuildOptions{TrailingNewline: true})
+	require.NoError(t, err)
+	require.Regexp(t, "}\n$", result)
+	require.NotRegexp(t, "\n\n$", result)
+
+	result, err = hclbuilder.New().BuildWith(hclbuilder.BuildOptions{TrailingNewline: true})
+	require.NoError(t, err)
+	require.Empty(t, result)
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
@@ -19,14 +18,19 @@ type Builder struct {
 	file             *hclwrite.File
 	ProviderType     ProviderType
 	ProviderProperty ProviderType
-	upsertedBuilders map[*Builder]bool
+	upsertedBuilders builderSet
+	errs             []error
+	// copyOnWrite makes mutators return a modified copy (see CopyOnWrite).
+	copyOnWrite bool
+	// providerAliases are the provider configurations new resources refer to
+	// (see UseProvider).
+	providerAliases map[ProviderType]string
 }
 
 // New creates a new empty HCL builder
 func New() *Builder {
 	return &Builder{
-		file:             hclwrite.NewEmptyFile(),
-		upsertedBuilders: make(map[*Builder]bool),
+		file: hclwrite.NewEmptyFile(),
 	}
 }
 
@@ -35,22 +39,23 @@ func NewWithProvider(provider ProviderType, serverURL string) *Builder {
 	return New().WithProvider(provider, serverURL)
 }
 
-// FromFile loads an HCL configuration from a file
+// FromFile loads an HCL configuration from a file. Files ending with .json
+// are read in Terraform JSON syntax (see FromJSON).
 func FromFile(path string) (*Builder, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
+	if strings.HasSuffix(path, ".json") {
+		return FromJSON(string(content))
+	}
 
 	file, diags := hclwrite.ParseConfig(content, path, hcl.Pos{Line: 1, Column: 1})
 	if diags.HasErrors() {
 		return nil, fmt.Errorf("parsing HCL: %s", diags.Error())
 	}
 
-	return &Builder{
-		file:             file,
-		upsertedBuilders: make(map[*Builder]bool),
-	}, nil
+	return &Builder{file: file}, nil
 }
 
 // FromString parses an HCL configuration from a string
@@ -60,10 +65,7 @@ func FromString(content string) (*Builder, error) {
 		return nil, fmt.Errorf("parsing HCL: %s", diags.Error())
 	}
 
-	return &Builder{
-		file:             file,
-		upsertedBuilders: make(map[*Builder]bool),
-	}, nil
+	return &Builder{file: file}, nil
 }
 
 // Build returns the HCL configuration as a string
@@ -71,201 +73,107 @@ func (b *Builder) Build() string {
 	return string(b.file.Bytes())
 }
 
-// WithProvider adds a provider block to the builder
+// WithProvider adds a provider block to the builder, or replaces the default
+// (not aliased) one. Use WithProviderOptions for other settings and aliases.
 func (b *Builder) WithProvider(provider ProviderType, serverURL string) *Builder {
-	b.ProviderType = provider
-
 	if serverURL == "" {
 		serverURL = "http://localhost:5681"
 	}
 
-	b.SetBlock(fmt.Sprintf("provider.%s", provider), map[string]any{
-		"server_url": serverURL,
-	})
-
-	return b
+	return b.WithProviderOptions(provider, ProviderOptions{ServerURL: serverURL})
 }
 
-// Upsert embeds another builder's content into this builder
+// Upsert embeds another builder's content into this builder. Blocks are
+// matched by identity (type and labels, plus the alias of providers and the
+// address of import, moved and removed blocks): a block that already exists
+// is replaced in place, other blocks are appended. Blocks are shared with the
+// other builder, so later changes made through it are visible in this
+// builder. Use UpsertWith to deep-merge existing blocks instead.
 func (b *Builder) Upsert(other *Builder) *Builder {
-	if other == nil || other.file == nil {
-		return b
-	}
-
-	// Check if this builder has already been upserted
-	if b.upsertedBuilders[other] {
-		return b
-	}
-
-	// Mark this builder as upserted
-	b.upsertedBuilders[other] = true
-
-	// Merge all blocks from the other builder into this one
-	for _, block := range other.file.Body().Blocks() {
-		b.file.Body().AppendBlock(block)
-	}
-
-	// Merge all attributes from the other builder into this one
-	for name, attr := range other.file.Body().Attributes() {
-		b.file.Body().SetAttributeRaw(name, attr.Expr().BuildTokens(nil))
-	}
-
-	return b
+	return b.UpsertWith(other, MergeReplace)
 }
 
-// Remove removes a builder's content from this builder
+// Remove removes a builder's content from this builder: every block with the
+// same identity as one of the other builder's blocks (see Upsert), whatever
+// its kind, and the other builder's top-level attributes.
+//
+// Blocks and attributes that are not in this builder are skipped and
+// recorded as errors (see Err).
 func (b *Builder) Remove(other *Builder) *Builder {
 	if other == nil || other.file == nil {
 		return b
 	}
+	b = b.mutable()
 
 	// Unmark this builder as upserted
-	delete(b.upsertedBuilders, other)
+	b.upsertedBuilders.remove(other)
 
-	// Get the resource path from the other builder to identify what to remove
-	resourcePath := other.ResourcePath()
-	if resourcePath == "" {
-		return b
+	body := b.file.Body()
+	for _, block := range other.file.Body().Blocks() {
+		existing := findBlockByKey(body, blockKey(block))
+		if existing == nil {
+			b.addError("Remove", blockPath(block), ErrBlockNotFound)
+			continue
+		}
+		body.RemoveBlock(existing)
 	}
 
-	// Parse the resource path to get type and name
-	parts := strings.Split(resourcePath, ".")
-	if len(parts) != 2 {
-		return b
+	for _, name := range attributeNames(other.file.Body()) {
+		if body.RemoveAttribute(name) == nil {
+			b.addError("Remove", name, ErrAttributeNotFound)
+		}
 	}
-	resourceType := parts[0]
-	resourceName := parts[1]
 
-	// Remove the matching block
-	return b.RemoveBlock(fmt.Sprintf("resource.%s.%s", resourceType, resourceName))
+	return b
 }
 
-// WriteFile writes the HCL configuration to a file
+// WriteFile writes the HCL configuration to a file. Files ending with .json
+// are written in Terraform JSON syntax (see BuildJSON).
 func (b *Builder) WriteFile(path string) error {
+	if strings.HasSuffix(path, ".json") {
+		content, err := b.jsonBytes()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, 0o600)
+	}
 	return os.WriteFile(path, b.file.Bytes(), 0o600)
 }
 
 // AddAttribute adds or updates an attribute on the first block in this builder.
-// This is designed for builders that contain a single resource/block.
+// This is designed for builders that contain a single resource/block; use
+// Block to target a specific block.
 // The path uses dot notation for nested attributes.
 // Value can be a Go value or a string containing HCL expression.
 // Example: builder.AddAttribute("skip_creating_initial_policies", `["*"]`)
 // Example: builder.AddAttribute("routing.default_forbid_mesh_external_service_access", "true")
 // Example: builder.AddAttribute("constraints.dataplane_proxy.requirements", `[{ tags = { key = "a" } }]`)
+// Example: builder.AddAttribute("spec.from[0].default.action", `"Deny"`)
 func (b *Builder) AddAttribute(path string, value any) *Builder {
-	blocks := b.file.Body().Blocks()
-	if len(blocks) == 0 {
-		// No blocks, can't add attribute
-		return b
-	}
-
-	// Work with the first block (typical use case: single resource)
-	block := blocks[0]
-	parts := strings.Split(path, ".")
-
-	// If value is a string, try to parse it as HCL
-	useRaw := false
-	if strValue, ok := value.(string); ok {
-		parsedValue := parseHCLValue(strValue)
-		if parsedValue != nil {
-			value = parsedValue
-		} else if isHCLExpression(strValue) {
-			// It's a valid HCL expression but can't be evaluated (e.g., a reference)
-			// Use SetAttributeRaw to preserve it as-is
-			useRaw = true
-			value = strValue
-		}
-	}
-
-	if len(parts) == 1 {
-		// Simple attribute
-		if useRaw {
-			block.Body().SetAttributeRaw(path, hclwrite.Tokens{
-				{Type: hclsyntax.TokenIdent, Bytes: []byte(value.(string))},
-			})
-		} else {
-			block.Body().SetAttributeValue(path, convertToCtyValue(value))
-		}
-	} else {
-		// Nested attribute - need to merge with existing value
-		rootAttr := parts[0]
-
-		// Get existing value if present
-		var existingValue any
-		if attr := block.Body().GetAttribute(rootAttr); attr != nil {
-			// Convert the expression tokens to string and parse it
-			exprTokens := attr.Expr().BuildTokens(nil)
-			exprStr := string(exprTokens.Bytes())
-			existingValue = parseHCLValue(exprStr)
-		}
-
-		// Build the new nested structure
-		newNested := buildNestedStructureRecursive(parts[1:], value)
-
-		// Merge with existing value if it's a map
-		var finalValue any
-		if existingMap, ok := existingValue.(map[string]any); ok {
-			if newNestedMap, ok := newNested.(map[string]any); ok {
-				finalValue = deepMerge(existingMap, newNestedMap)
-			} else {
-				finalValue = newNested
-			}
-		} else {
-			finalValue = newNested
-		}
+	return b.firstBlock().AddAttribute(path, value).builder
+}
 
-		// Set the root attribute with the merged structure
-		block.Body().SetAttributeValue(rootAttr, convertToCtyValue(finalValue))
-	}
+// AppendAttribute appends a value to the list at path on the first block in
+// this builder, creating the list if it does not exist.
+// Example: builder.AppendAttribute("spec.from[0].target_ref.proxy_types", "Sidecar")
+func (b *Builder) AppendAttribute(path string, value any) *Builder {
+	return b.firstBlock().AppendAttribute(path, value).builder
+}
 
-	return b
+// InsertAttribute inserts a value into a list on the first block in this
+// builder, before the element at the index path ends with.
+// Example: builder.InsertAttribute("spec.from[0]", map[string]any{"target_ref": map[string]any{"kind": "Mesh"}})
+func (b *Builder) InsertAttribute(path string, value any) *Builder {
+	return b.firstBlock().InsertAttribute(path, value).builder
 }
 
 // RemoveAttribute removes an attribute from the first block in this builder.
 // Uses dot notation for nested attributes.
 // Example: builder.RemoveAttribute("routing.default_forbid_mesh_external_service_access")
 // will remove only the nested field, leaving other fields in "routing" intact.
+// Example: builder.RemoveAttribute("spec.from[1]") removes the second element of the list.
 func (b *Builder) RemoveAttribute(path string) *Builder {
-	blocks := b.file.Body().Blocks()
-	if len(blocks) == 0 {
-		return b
-	}
-
-	block := blocks[0]
-	parts := strings.Split(path, ".")
-
-	if len(parts) == 1 {
-		// Simple attribute
-		block.Body().RemoveAttribute(path)
-	} else {
-		// Nested attribute - read the structure, modify it, and write it back
-		rootAttr := parts[0]
-
-		// Get existing value if present
-		attr := block.Body().GetAttribute(rootAttr)
-		if attr == nil {
-			// Attribute doesn't exist, nothing to remove
-			return b
-		}
-
-		// Convert the expression tokens to string and parse it
-		exprTokens := attr.Expr().BuildTokens(nil)
-		exprStr := string(exprTokens.Bytes())
-		existingValue := parseHCLValue(exprStr)
-
-		// Navigate to the nested structure and remove the specific field
-		if modified, ok := removeFromNested(existingValue, parts[1:]); ok {
-			if modified == nil {
-				// The entire structure was removed
-				block.Body().RemoveAttribute(rootAttr)
-			} else {
-				// Update with the modified structure
-				block.Body().SetAttributeValue(rootAttr, convertToCtyValue(modified))
-			}
-		}
-	}
-
-	return b
+	return b.firstBlock().RemoveAttribute(path).builder
 }
 
 // isHCLExpression checks if a string is a valid HCL expression (even if it can't be evaluated)
@@ -340,7 +248,7 @@ func convertCtyToGo(val cty.Value) any {
 	case ty == cty.Bool:
 		return val.True()
 	case ty.IsListType() || ty.IsTupleType():
-		var result []any
+		result := []any{}
 		it := val.ElementIterator()
 		for it.Next() {
 			_, elemVal := it.Element()
@@ -361,194 +269,145 @@ func convertCtyToGo(val cty.Value) any {
 	}
 }
 
-// deepMerge recursively merges two maps, with values from 'b' taking precedence
-func deepMerge(a, b map[string]any) map[string]any {
-	result := make(map[string]any)
-
-	// Copy all keys from 'a'
-	for k, v := range a {
-		result[k] = v
-	}
-
-	// Merge keys from 'b'
-	for k, v := range b {
-		if existingV, exists := result[k]; exists {
-			// If both values are maps, merge them recursively
-			if existingMap, ok := existingV.(map[string]any); ok {
-				if newMap, ok := v.(map[string]any); ok {
-					result[k] = deepMerge(existingMap, newMap)
-					continue
-				}
-			}
-		}
-		// Otherwise, value from 'b' takes precedence
-		result[k] = v
-	}
-
-	return result
-}
-
-// buildNestedStructure builds a nested map from dot-separated path and value
-
-func buildNestedStructureRecursive(parts []string, value any) any {
-	if len(parts) == 1 {
-		return map[string]any{parts[0]: value}
-	}
-	return map[string]any{
-		parts[0]: buildNestedStructureRecursive(parts[1:], value),
-	}
-}
-
-// removeFromNested removes a nested field from a map structure.
-// Returns the modified structure and true if successful, or the original value and false if not found.
-// If the removal results in an empty map, returns nil and true.
-func removeFromNested(value any, path []string) (any, bool) {
-	if len(path) == 0 {
-		return nil, true
-	}
-
-	valueMap, ok := value.(map[string]any)
-	if !ok {
-		return value, false
-	}
-
-	if len(path) == 1 {
-		// This is the field to remove
-		if _, exists := valueMap[path[0]]; !exists {
-			return value, false
-		}
-
-		// Make a copy and remove the field
-		result := make(map[string]any)
-		for k, v := range valueMap {
-			if k != path[0] {
-				result[k] = v
-			}
-		}
-
-		// If the map is now empty, return nil to indicate removal of parent
-		if len(result) == 0 {
-			return nil, true
-		}
-
-		return result, true
-	}
-
-	// Recursive case
-	nestedValue, exists := valueMap[path[0]]
-	if !exists {
-		return value, false
-	}
-
-	modifiedNested, ok := removeFromNested(nestedValue, path[1:])
-	if !ok {
-		return value, false
-	}
-
-	// Make a copy and update the nested field
-	result := make(map[string]any)
-	for k, v := range valueMap {
-		if k == path[0] {
-			if modifiedNested != nil {
-				result[k] = modifiedNested
-			}
-			// else: don't include it (it was removed)
-		} else {
-			result[k] = v
-		}
-	}
-
-	// If the map is now empty, return nil to indicate removal of parent
-	if len(result) == 0 {
-		return nil, true
-	}
-
-	return result, true
-}
-
 // SetAttribute sets an attribute value at the given path.
 //
-// Path format: "block_type.block_label.attribute_name" or nested paths.
+// Path format: "block_type.block_labels....attribute_name", where the number
+// of labels depends on the block type (two for resource and data, none for
+// terraform and locals, one otherwise). Parts between the labels and the
+// attribute name are nested blocks, e.g. "resource.type.name.lifecycle.prevent_destroy".
+// Labels containing dots must be quoted (see JoinPath).
 // Example: "variable.name.default" sets variable "name" { default = value }.
+// Strings are written as quoted strings; use Ref, Raw or Func for expressions.
 //
-// If the path is invalid (fewer than 3 parts), this method does nothing.
-func (b *Builder) SetAttribute(path string, value any) {
-	parts := strings.Split(path, ".")
-	if len(parts) < 3 {
-		// Need at least: block_type.block_label.attribute_name
-		return
+// Blocks are created if they do not exist. If the path is invalid, this
+// method does nothing and records an error (see Err).
+func (b *Builder) SetAttribute(path string, value any) *Builder {
+	b = b.mutable()
+	parts, err := splitBlockPath(path)
+	if err != nil {
+		b.addError("SetAttribute", path, err)
+		return b
 	}
 
-	body := b.file.Body()
-	attributeName := parts[len(parts)-1]
-
-	// Navigate/create the block structure (all parts except the last, which is the attribute)
-	for i := 0; i < len(parts)-1; i += 2 {
-		if i+1 >= len(parts)-1 {
-			// We've reached the end, shouldn't happen with proper path
-			break
-		}
-
-		blockType := parts[i]
-		blockLabel := parts[i+1]
+	labelCount, _ := blockLabelCount(parts[0])
+	if len(parts) < labelCount+2 {
+		// Need at least: block_type, its labels and attribute_name
+		b.addError("SetAttribute", path, fmt.Errorf("%w: expected at least %d parts (block_type, %d label(s) and attribute_name), got %d", ErrInvalidPath, labelCount+2, labelCount, len(parts)))
+		return b
+	}
 
-		// Check if this is the last block before the attribute
-		if i+2 == len(parts)-1 {
-			// This is the block that should contain the attribute
-			block := findOrCreateBlock(body, blockType, []string{blockLabel})
-			block.Body().SetAttributeValue(attributeName, convertToCtyValue(value))
-			return
-		}
+	tokens, err := valueTokens(value)
+	if err != nil {
+		b.addError("SetAttribute", path, err)
+		return b
+	}
 
-		// Navigate deeper into nested blocks
-		block := findOrCreateBlock(body, blockType, []string{blockLabel})
-		body = block.Body()
+	// Navigate/create the block structure, then the nested blocks
+	block := findOrCreateBlock(b.file.Body(), parts[0], parts[1:labelCount+1])
+	for _, nestedType := range parts[labelCount+1 : len(parts)-1] {
+		block = findOrCreateBlock(block.Body(), nestedType, nil)
 	}
+	block.Body().SetAttributeRaw(parts[len(parts)-1], tokens)
+
+	return b
 }
 
-// SetBlock creates or replaces a block with the given attributes.
+// SetBlock creates or replaces a block with the given attributes. An existing
+// block is replaced in place, and keeps the comments above it.
 //
-// Path format: "block_type.block_label1.block_label2...".
-// Example: "resource.aws_instance.web".
+// Path format: "block_type.block_label1.block_label2...". Labels containing
+// dots must be quoted (see JoinPath).
+// Example: "resource.aws_instance.web", `provider."kong-mesh.eu"`.
 //
-// If the path is invalid (fewer than 2 parts), this method does nothing.
-// Nested maps are treated as nested blocks.
-func (b *Builder) SetBlock(path string, attributes map[string]any) {
-	parts := strings.Split(path, ".")
-	if len(parts) < 2 {
-		return
+// If the path is invalid (wrong number of labels for the block type), this
+// method does nothing and records an error (see Err).
+// Nested maps are treated as object attributes; use NestedBlock values
+// for nested blocks, and Ref, Raw or Func for expressions.
+func (b *Builder) SetBlock(path string, attributes map[string]any) *Builder {
+	b = b.mutable()
+	parts, err := splitTopLevelBlockPath(path)
+	if err != nil {
+		b.addError("SetBlock", path, err)
+		return b
 	}
 
 	blockType := parts[0]
 	labels := parts[1:]
 
-	body := b.file.Body()
+	// Create new block
+	block, err := newBlock(blockType, NestedBlock{Labels: labels, Attributes: attributes})
+	if err != nil {
+		b.addError("SetBlock", path, err)
+		return b
+	}
 
-	// Remove existing block if it exists
-	removeBlock(body, blockType, labels)
+	// Replace existing block in place, keeping the comments describing it
+	body := b.file.Body()
+	if existing := findBlock(body, blockType, labels); existing != nil {
+		replaceBlock(body, existing, block)
+	} else {
+		body.AppendBlock(block)
+	}
 
-	// Create new block
-	block := body.AppendNewBlock(blockType, labels)
-	setBlockAttributes(block.Body(), attributes)
+	return b
 }
 
 // RemoveBlock removes a block at the given path.
 //
-// If the path is invalid or the block doesn't exist, this method does nothing.
+// If the path is invalid or the block doesn't exist, this method does nothing
+// and records an error (see Err).
 func (b *Builder) RemoveBlock(path string) *Builder {
-	parts := strings.Split(path, ".")
-	if len(parts) < 2 {
+	b = b.mutable()
+	parts, err := splitTopLevelBlockPath(path)
+	if err != nil {
+		b.addError("RemoveBlock", path, err)
 		return b
 	}
 
 	blockType := parts[0]
 	labels := parts[1:]
 
-	removeBlock(b.file.Body(), blockType, labels)
+	if !removeBlock(b.file.Body(), blockType, labels) {
+		b.addError("RemoveBlock", path, ErrBlockNotFound)
+	}
 
 	return b
 }
 
+// blockLabelCount returns the number of labels of a top-level block type, and
+// whether the type is known. Unknown block types are assumed to have one label.
+func blockLabelCount(blockType string) (int, bool) {
+	switch blockType {
+	case "resource", "data", "ephemeral":
+		return 2, true
+	case "provider", "variable", "output", "module", "check":
+		return 1, true
+	case "terraform", "locals", "import", "moved", "removed":
+		return 0, true
+	default:
+		return 1, false
+	}
+}
+
+// splitTopLevelBlockPath splits the path of a top-level block and checks its
+// number of labels: exactly the expected count for known block types, at
+// least one for other types.
+func splitTopLevelBlockPath(path string) ([]string, error) {
+	parts, err := splitBlockPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	labelCount, known := blockLabelCount(parts[0])
+	switch {
+	case !known && len(parts) < 2:
+		return nil, fmt.Errorf("%w: expected at least 2 parts (block_type.block_label), got %d", ErrInvalidPath, len(parts))
+	case known && len(parts)-1 != labelCount:
+		return nil, fmt.Errorf("%w: %s blocks have %d label(s), got %d", ErrInvalidPath, parts[0], labelCount, len(parts)-1)
+	}
+	return parts, nil
+}
+
 // Helper functions
 
 func findBlock(body *hclwrite.Body, blockType string, labels []string) *hclwrite.Block {
@@ -568,11 +427,12 @@ func findOrCreateBlock(body *hclwrite.Body, blockType string, labels []string) *
 	return block
 }
 
-func removeBlock(body *hclwrite.Body, blockType string, labels []string) {
+func removeBlock(body *hclwrite.Body, blockType string, labels []string) bool {
 	block := findBlock(body, blockType, labels)
-	if block != nil {
-		body.RemoveBlock(block)
+	if block == nil {
+		return false
 	}
+	return body.RemoveBlock(block)
 }
 
 func matchLabels(blockLabels, targetLabels []string) bool {
@@ -587,7 +447,10 @@ func matchLabels(blockLabels, targetLabels []string) bool {
 	return true
 }
 
-func setBlockAttributes(body *hclwrite.Body, attributes map[string]any) {
+// setBlockAttributes writes attributes, and nested blocks given as NestedBlock
+// values, to body. It returns an error if a value cannot be converted, in
+// which case body is left partially written.
+func setBlockAttributes(body *hclwrite.Body, attributes map[string]any) error {
 	// Sort keys for deterministic output
 	keys := make([]string, 0, len(attributes))
 	for key := range attributes {
@@ -595,53 +458,47 @@ func setBlockAttributes(body *hclwrite.Body, attributes map[string]any) {
 	}
 	sort.Strings(keys)
 
+	// Attributes go first, followed by nested blocks
 	for _, key := range keys {
-		value := attributes[key]
-		// All values are set as attributes (including maps which become object values)
-		// Nested blocks must be created explicitly via SetBlock, not through this function
-		body.SetAttributeValue(key, convertToCtyValue(value))
-	}
-}
-
-func convertToCtyValue(value any) cty.Value {
-	switch v := value.(type) {
-	case string:
-		return cty.StringVal(v)
-	case int:
-		return cty.NumberIntVal(int64(v))
-	case int64:
-		return cty.NumberIntVal(v)
-	case float64:
-		return cty.NumberFloatVal(v)
-	case bool:
-		return cty.BoolVal(v)
-	case []string:
-		if len(v) == 0 {
-			return cty.ListValEmpty(cty.String)
-		}
-		vals := make([]cty.Value, len(v))
-		for i, s := range v {
-			vals[i] = cty.StringVal(s)
-		}
-		return cty.ListVal(vals)
-	case []any:
-		if len(v) == 0 {
-			return cty.EmptyTupleVal
+		switch value := attributes[key].(type) {
+		case NestedBlock, []NestedBlock:
+			continue
+		default:
+			// Maps become object values, nested blocks must be given as NestedBlock
+			tokens, err := valueTokens(value)
+			if err != nil {
+				return fmt.Errorf("attribute %q: %w", key, err)
+			}
+			body.SetAttributeRaw(key, tokens)
 		}
-		vals := make([]cty.Value, len(v))
-		for i, item := range v {
-			vals[i] = convertToCtyValue(item)
+	}
+	for _, key := range keys {
+		var nestedBlocks []NestedBlock
+		switch value := attributes[key].(type) {
+		case NestedBlock:
+			nestedBlocks = []NestedBlock{value}
+		case []NestedBlock:
+			nestedBlocks = value
 		}
-		return cty.ListVal(vals)
-	case map[string]any:
-		vals := make(map[string]cty.Value)
-		for k, item := range v {
-			vals[k] = convertToCtyValue(item)
+		for _, nested := range nestedBlocks {
+			block, err := newBlock(key, nested)
+			if err != nil {
+				return fmt.Errorf("block %q: %w", key, err)
+			}
+			body.AppendBlock(block)
 		}
-		return cty.ObjectVal(vals)
-	default:
-		return cty.StringVal(fmt.Sprintf("%v", v))
 	}
+	return nil
+}
+
+// newBlock returns a new block, not yet attached to any body, so that nothing
+// is written if one of the values cannot be converted.
+func newBlock(blockType string, nested NestedBlock) (*hclwrite.Block, error) {
+	block := hclwrite.NewBlock(blockType, nested.Labels)
+	if err := setBlockAttributes(block.Body(), nested.Attributes); err != nil {
+		return nil, err
+	}
+	return block, nil
 }
 
 // Provider-specific helper methods
@@ -659,24 +516,27 @@ func (b *Builder) RemoveMesh(meshResourceName string) *Builder {
 	return b.RemoveBlock(fmt.Sprintf("resource.%s_mesh.%s", b.ProviderType, meshResourceName))
 }
 
-// AddControlPlane adds a mesh control plane resource (for Konnect providers)
+// AddControlPlane adds a mesh control plane resource (for Konnect providers).
+// The resource refers to the aliased provider configuration in use, if any
+// (see UseProvider).
 func (b *Builder) AddControlPlane(resourceName, name, description string) *Builder {
 	attrs := map[string]any{
 		"name":        name,
 		"description": description,
 	}
+	b.addProviderRef(attrs)
 
-	b.SetBlock(fmt.Sprintf("resource.%s_mesh_control_plane.%s", b.ProviderProperty, resourceName), attrs)
-	return b
+	return b.SetBlock(fmt.Sprintf("resource.%s_mesh_control_plane.%s", b.ProviderProperty, resourceName), attrs)
 }
 
-// AddPolicy adds a policy resource
+// AddPolicy adds a policy resource. The resource refers to the provider
+// configuration in use, e.g. kong-mesh or kong-mesh.eu (see ProviderRef).
 func (b *Builder) AddPolicy(policyType, policyName, policyResourceName, meshRef string, spec map[string]any) *Builder {
 	// Convert policy type from snake_case to PascalCase for the type attribute
 	pascalCaseType := resourceTypeToPolicyType(policyType)
 
 	attrs := map[string]any{
-		"provider": strings.ToLower(string(b.ProviderProperty)),
+		"provider": b.ProviderRef(b.ProviderProperty),
 		"type":     pascalCaseType,
 		"name":     policyName,
 		"mesh":     meshRef,
@@ -687,127 +547,19 @@ func (b *Builder) AddPolicy(policyType, policyName, policyResourceName, meshRef
 		attrs[k] = v
 	}
 
-	b.SetBlock(fmt.Sprintf("resource.%s_%s.%s", b.ProviderProperty, policyType, policyResourceName), attrs)
-	return b
+	return b.SetBlock(fmt.Sprintf("resource.%s_%s.%s", b.ProviderProperty, policyType, policyResourceName), attrs)
 }
 
+// ResourceName returns the name of the first block in this builder
+// (e.g., "my_meshcontrolplane"). Returns empty string if no blocks exist.
 func (b *Builder) ResourceName() string {
-	blocks := b.file.Body().Blocks()
-	if len(blocks) == 0 {
-		return ""
-	}
-
-	block := blocks[0]
-	labels := block.Labels()
-	if len(labels) == 0 {
-		return ""
-	}
-
-	// For resources, the first label is the resource type, second is the name
-	// e.g., resource "konnect_mesh_control_plane" "my_meshcontrolplane"
-	// labels[0] = "konnect_mesh_control_plane", labels[1] = "my_meshcontrolplane"
-	if len(labels) >= 2 {
-		return labels[1]
-	}
-
-	return labels[0]
+	return b.firstBlock().ResourceName()
 }
 
 // ResourcePath returns the full Terraform resource path (e.g., "konnect_mesh_control_plane.my_meshcontrolplane")
 // from the first block in this builder. Returns empty string if no blocks exist.
 func (b *Builder) ResourcePath() string {
-	blocks := b.file.Body().Blocks()
-	if len(blocks) == 0 {
-		return ""
-	}
-
-	block := blocks[0]
-	blockType := block.Type()
-	labels := block.Labels()
-
-	// Only process resource blocks
-	if blockType != "resource" {
-		return ""
-	}
-
-	if len(labels) < 2 {
-		return ""
-	}
-
-	// For resources: resource "type" "name" -> type.name
-	// e.g., resource "konnect_mesh_control_plane" "cp1" -> konnect_mesh_control_plane.cp1
-	return fmt.Sprintf("%s.%s", labels[0], labels[1])
-}
-
-// DependsOn adds a depends_on attribute to the first block in this builder,
-// referencing the resource path from the other builder.
-// Example:
-//
-//	cp := hclbuilder.New()
-//	mesh := hclbuilder.New()
-//	mesh.DependsOn(cp)
-//
-// This will add: depends_on = [konnect_mesh_control_plane.my_meshcontrolplane]
-func (b *Builder) DependsOn(other *Builder) *Builder {
-	blocks := b.file.Body().Blocks()
-	if len(blocks) == 0 {
-		return b
-	}
-
-	resourcePath := other.ResourcePath()
-	if resourcePath == "" {
-		return b
-	}
-
-	block := blocks[0]
-
-	// Get existing depends_on if present
-	var existingDeps []string
-	if attr := block.Body().GetAttribute("depends_on"); attr != nil {
-		// Parse existing depends_on from raw tokens
-		exprTokens := attr.Expr().BuildTokens(nil)
-
-		// Extract identifiers from the token stream
-		// Format: [ identifier, identifier, ... ]
-		inList := false
-		for _, token := range exprTokens {
-			if token.Type == hclsyntax.TokenOBrack {
-				inList = true
-				continue
-			}
-			if token.Type == hclsyntax.TokenCBrack {
-				break
-			}
-			if inList && token.Type == hclsyntax.TokenIdent {
-				existingDeps = append(existingDeps, string(token.Bytes))
-			}
-		}
-	}
-
-	// Check if dependency already exists
-	for _, dep := range existingDeps {
-		if dep == resourcePath {
-			return b
-		}
-	}
-
-	// Upsert new dependency
-	existingDeps = append(existingDeps, resourcePath)
-
-	// Build depends_on as raw tokens to avoid quoting the references
-	var tokens hclwrite.Tokens
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenOBrack, Bytes: []byte{'['}})
-	for i, dep := range existingDeps {
-		if i > 0 {
-			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte{',', ' '}})
-		}
-		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(dep)})
-	}
-	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte{']'}})
-
-	block.Body().SetAttributeRaw("depends_on", tokens)
-
-	return b
+	return b.firstBlock().ResourcePath()
 }
 
 // Helper functions for policy type conversion
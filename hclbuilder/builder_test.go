@@ -1,6 +1,7 @@
 package hclbuilder_test
 
 import (
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -32,7 +33,28 @@ func assertGoldenFile(t *testing.T, goldenFile string, actual string) {
 
 	expected, err := os.ReadFile(goldenFile)
 	require.NoError(t, err, "reading golden file")
-	require.Equal(t, string(expected), actual)
+	require.Equal(t, string(expected), actual, semanticDiff(string(expected), actual))
+}
+
+// semanticDiff describes the differences between the golden and actual HCL,
+// to make golden file mismatches easier to read
+func semanticDiff(expected, actual string) string {
+	if expected == actual {
+		return ""
+	}
+	expectedBuilder, err := hclbuilder.FromString(expected)
+	if err != nil {
+		return ""
+	}
+	actualBuilder, err := hclbuilder.FromString(actual)
+	if err != nil {
+		return ""
+	}
+	changes := hclbuilder.Diff(expectedBuilder, actualBuilder)
+	if len(changes) == 0 {
+		return "golden file and actual output only differ in formatting"
+	}
+	return "changes from golden file:\n" + changes.String()
 }
 
 // Test New() - empty builder
@@ -85,6 +107,36 @@ func TestSetAttribute_Multiple(t *testing.T) {
 	assertGoldenFile(t, goldenFile, result)
 }
 
+// Test SetAttribute() - paths are split according to the label count of the block type
+func TestSetAttribute_LabelCount(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetAttribute("terraform.required_version", ">= 1.5")
+	builder.SetAttribute("locals.mesh_name", "default")
+	builder.SetAttribute("variable.mesh_name.type", hclbuilder.Ref("string"))
+	builder.SetAttribute("resource.konnect_mesh.m.name", "m")
+	builder.SetAttribute("resource.konnect_mesh.m.lifecycle.prevent_destroy", true)
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "set-attribute-label-count.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	// Resources keep both labels and the attribute, instead of a one-label
+	// block without the attribute
+	require.Contains(t, result, `resource "konnect_mesh" "m" {`)
+	require.NotContains(t, result, `resource "konnect_mesh" {`)
+	require.True(t, builder.Block("resource.konnect_mesh.m").Exists())
+}
+
+// Test SetAttribute() - too few parts for the block type
+func TestSetAttribute_InvalidPath(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetAttribute("resource.konnect_mesh.name", "m")
+
+	require.ErrorIs(t, builder.Err(), hclbuilder.ErrInvalidPath)
+	require.Empty(t, builder.Build())
+}
+
 // Test SetBlock() - simple
 func TestSetBlock_Simple(t *testing.T) {
 	builder := hclbuilder.New()
@@ -127,7 +179,7 @@ func TestRemoveAttribute(t *testing.T) {
 	builder := hclbuilder.New()
 	builder.SetAttribute("resource.kong-mesh_mesh.default.name", "mesh-1")
 	builder.SetAttribute("resource.kong-mesh_mesh.default.type", "Mesh")
-	builder.RemoveAttribute("resource.kong-mesh_mesh.default.type")
+	builder.Block("resource.kong-mesh_mesh.default").RemoveAttribute("type")
 
 	result := builder.Build()
 	goldenFile := filepath.Join("testdata", "remove-attribute.golden.tf")
@@ -514,6 +566,95 @@ func TestSetBlock_NumericValues(t *testing.T) {
 	assertGoldenFile(t, goldenFile, result)
 }
 
+// Test SetBlock() - values of any Go type, and lists mixing objects of different shapes
+func TestSetBlock_GoValues(t *testing.T) {
+	type targetRef struct {
+		Kind       string   `cty:"kind"`
+		Name       *string  `cty:"name"`
+		ProxyTypes []string `cty:"proxy_types"`
+		internal   string
+	}
+	name := "backend"
+
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.test.values", map[string]any{
+		"int8_value":    int8(-8),
+		"int32_value":   int32(32),
+		"uint_value":    uint(7),
+		"uint64_value":  uint64(18446744073709551615),
+		"float32_value": float32(0.1),
+		"nil_value":     nil,
+		"nil_pointer":   (*string)(nil),
+		"pointer":       &name,
+		"labels":        map[string]string{"team": "mesh"},
+		"empty_ints":    []int{},
+		"rules": []map[string]any{
+			{"target_ref": map[string]any{"kind": "Mesh"}},
+			{"target_ref": map[string]any{"kind": "MeshService", "proxy_types": []string{"Sidecar"}}},
+		},
+		"struct_value": targetRef{Kind: "MeshService", Name: &name, internal: "ignored"},
+		"structs": []targetRef{
+			{Kind: "Mesh", ProxyTypes: []string{"Gateway"}},
+			{Kind: "MeshService", Name: &name},
+		},
+	})
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "set-block-go-values.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test SetBlock() and SetAttribute() - values that cannot be converted are recorded, nothing is written
+func TestSetBlock_UnsupportedValues(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.test.values", map[string]any{
+		"name":    "kept out",
+		"handler": func() {},
+	})
+	builder.SetBlock("resource.test.keys", map[string]any{
+		"ports": map[int]string{80: "http"},
+	})
+	builder.SetBlock("resource.test.struct", map[string]any{
+		"value": struct{ Name string }{Name: "no tags"},
+	})
+	builder.SetAttribute("variable.test.default", make(chan int))
+
+	require.Empty(t, builder.Build())
+	err := builder.Err()
+	requirePathError(t, err, "SetBlock", "resource.test.values", hclbuilder.ErrUnsupportedValue)
+	require.ErrorContains(t, err, `attribute "handler"`)
+	require.ErrorContains(t, err, "map keys must be strings")
+	require.ErrorContains(t, err, "struct fields need `cty` tags")
+	require.ErrorContains(t, err, `SetAttribute "variable.test.default"`)
+}
+
+// Test SetBlock() and SetAttribute() - NaN and infinities have no HCL literal
+func TestSetBlock_NonFiniteFloats(t *testing.T) {
+	tests := map[string]any{
+		"float64 NaN":  math.NaN(),
+		"float64 +Inf": math.Inf(1),
+		"float64 -Inf": math.Inf(-1),
+		"float32 NaN":  float32(math.NaN()),
+		"float32 +Inf": float32(math.Inf(1)),
+		"list":         []float64{1, math.Inf(-1)},
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := hclbuilder.New()
+			builder.SetBlock("resource.test.values", map[string]any{
+				"ratio": value,
+			})
+			builder.SetAttribute("variable.test.default", value)
+
+			require.Empty(t, builder.Build())
+			err := builder.Err()
+			requirePathError(t, err, "SetBlock", "resource.test.values", hclbuilder.ErrUnsupportedValue)
+			require.ErrorContains(t, err, `SetAttribute "variable.test.default"`)
+		})
+	}
+}
+
 // Test AddAttribute() - complex nested merge scenario
 func TestAddAttribute_ComplexDeepMerge(t *testing.T) {
 	inputFile := filepath.Join("testdata", "add-attribute-complex-deep-merge.input.tf")
@@ -750,3 +891,179 @@ func TestRemoveAttribute_DeeplyNested(t *testing.T) {
 	goldenFile := filepath.Join("testdata", "remove-attribute-deeply-nested.golden.tf")
 	assertGoldenFile(t, goldenFile, result)
 }
+
+// Test AddAttribute() - nested merge keeps references, function calls, heredocs and comments
+func TestAddAttribute_PreserveExpressions(t *testing.T) {
+	inputFile := filepath.Join("testdata", "add-attribute-preserve-expressions.input.tf")
+	policy, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	policy.AddAttribute("spec.target_ref.proxy_types", `["Sidecar"]`).
+		AddAttribute("spec.rules.default.action", "var.action").
+		AddAttribute("spec.labels", `merge(local.labels, { team = "kong" })`)
+
+	result, err := policy.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "add-attribute-preserve-expressions.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test AddAttribute() - keys added to objects written on a single line stay on that line
+func TestAddAttribute_SingleLineObject(t *testing.T) {
+	inputFile := filepath.Join("testdata", "add-attribute-single-line-object.input.tf")
+	policy, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	policy.AddAttribute("spec.target_ref.name", `"default"`).
+		AddAttribute("spec.labels.zone", `"eu"`).
+		AddAttribute("spec.tags.env", `"prod"`)
+
+	result, err := policy.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "add-attribute-single-line-object.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test AddAttribute() - keys are not added to values that are not objects
+func TestAddAttribute_NotObject(t *testing.T) {
+	inputFile := filepath.Join("testdata", "add-attribute-preserve-expressions.input.tf")
+	tests := map[string]string{
+		"reference":     "mesh.key",
+		"function call": "spec.labels.team",
+		"conditional":   "spec.from[0].default.action.key",
+	}
+	for name, path := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, err := hclbuilder.FromFile(inputFile)
+			require.NoError(t, err)
+			before := policy.Build()
+
+			policy.AddAttribute(path, `"kong"`)
+			requirePathError(t, policy.Err(), "AddAttribute", "resource.kong-mesh_mesh_traffic_permission.allow_all."+path, hclbuilder.ErrInvalidExpression)
+			require.Equal(t, before, policy.Build())
+		})
+	}
+
+	// A null value is replaced by an object
+	policy, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh" "default" {
+  spec = null
+}
+`)
+	require.NoError(t, err)
+	policy.AddAttribute("spec.key", `"kong"`)
+	require.NoError(t, policy.Err())
+	require.Contains(t, policy.Build(), `key = "kong"`)
+}
+
+// Test RemoveAttribute() - nested removal keeps references, function calls, heredocs and comments
+func TestRemoveAttribute_PreserveExpressions(t *testing.T) {
+	inputFile := filepath.Join("testdata", "remove-attribute-preserve-expressions.input.tf")
+	policy, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	policy.RemoveAttribute("spec.target_ref.name").
+		RemoveAttribute("spec.description")
+
+	result, err := policy.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "remove-attribute-preserve-expressions.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test AddAttribute() - list indices and wildcards in paths
+func TestAddAttribute_ListIndex(t *testing.T) {
+	inputFile := filepath.Join("testdata", "add-attribute-list-index.input.tf")
+	policy, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	policy.AddAttribute("spec.from[1].target_ref.name", `"frontend"`).
+		AddAttribute("spec.from[*].default.action", `"Deny"`).
+		AddAttribute("spec.target_ref.proxy_types[0]", `"Gateway"`).
+		AppendAttribute("spec.target_ref.proxy_types", `"Sidecar"`).
+		AppendAttribute("spec.from[0].target_ref.proxy_types", `"Sidecar"`).
+		InsertAttribute("spec.from[0]", map[string]any{
+			"target_ref": map[string]any{"kind": "MeshSubset"},
+		})
+
+	result, err := policy.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "add-attribute-list-index.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test RemoveAttribute() - list indices and wildcards in paths
+func TestRemoveAttribute_ListIndex(t *testing.T) {
+	inputFile := filepath.Join("testdata", "remove-attribute-list-index.input.tf")
+	policy, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+
+	policy.RemoveAttribute("spec.from[*].default").
+		RemoveAttribute("spec.from[0].target_ref").
+		RemoveAttribute("spec.target_ref.proxy_types[0]")
+
+	result, err := policy.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "remove-attribute-list-index.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test AddAttribute() and RemoveAttribute() - invalid or out of range list paths are recorded
+func TestAttribute_ListIndexErrors(t *testing.T) {
+	inputFile := filepath.Join("testdata", "add-attribute-list-index.input.tf")
+	policy, err := hclbuilder.FromFile(inputFile)
+	require.NoError(t, err)
+	before := policy.Build()
+
+	tests := []struct {
+		name   string
+		apply  func(*hclbuilder.Builder)
+		op     string
+		path   string
+		target error
+	}{
+		{"unterminated index", func(b *hclbuilder.Builder) { b.AddAttribute("spec.from[0", "x") }, "AddAttribute", "spec.from[0", hclbuilder.ErrInvalidPath},
+		{"negative index", func(b *hclbuilder.Builder) { b.AddAttribute("spec.from[-1].x", "x") }, "AddAttribute", "spec.from[-1].x", hclbuilder.ErrInvalidPath},
+		{"out of range", func(b *hclbuilder.Builder) { b.AddAttribute("spec.from[5].x", "x") }, "AddAttribute", "spec.from[5].x", hclbuilder.ErrAttributeNotFound},
+		{"not a list", func(b *hclbuilder.Builder) { b.AddAttribute("spec.target_ref[0]", "x") }, "AddAttribute", "spec.target_ref[0]", hclbuilder.ErrAttributeNotFound},
+		{"insert without index", func(b *hclbuilder.Builder) { b.InsertAttribute("spec.from", "x") }, "InsertAttribute", "spec.from", hclbuilder.ErrInvalidPath},
+		{"insert out of range", func(b *hclbuilder.Builder) { b.InsertAttribute("spec.from[3]", "x") }, "InsertAttribute", "spec.from[3]", hclbuilder.ErrAttributeNotFound},
+		{"remove out of range", func(b *hclbuilder.Builder) { b.RemoveAttribute("spec.from[2]") }, "RemoveAttribute", "spec.from[2]", hclbuilder.ErrAttributeNotFound},
+		{"wildcard on missing list", func(b *hclbuilder.Builder) { b.AddAttribute("spec.to[*].x", "x") }, "AddAttribute", "spec.to[*].x", hclbuilder.ErrAttributeNotFound},
+		{"remove wildcard on missing list", func(b *hclbuilder.Builder) { b.RemoveAttribute("spec.to[*]") }, "RemoveAttribute", "spec.to[*]", hclbuilder.ErrAttributeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := hclbuilder.FromFile(inputFile)
+			require.NoError(t, err)
+			tt.apply(builder)
+			requirePathError(t, builder.Err(), tt.op, "resource.kong-mesh_mesh_traffic_permission.allow_all."+tt.path, tt.target)
+			require.Equal(t, before, builder.Build())
+		})
+	}
+}
+
+// Test AddAttribute() and RemoveAttribute() - wildcards on an empty list are recorded like out of range indices
+func TestAttribute_WildcardEmptyList(t *testing.T) {
+	input := `
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  spec = {
+    from = []
+  }
+}
+`
+	builder, err := hclbuilder.FromString(input)
+	require.NoError(t, err)
+	before := builder.Build()
+
+	builder.AddAttribute("spec.from[*].default.action", `"Deny"`)
+	requirePathError(t, builder.Err(), "AddAttribute", "resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[*].default.action", hclbuilder.ErrAttributeNotFound)
+	require.Equal(t, before, builder.Build())
+
+	builder, err = hclbuilder.FromString(input)
+	require.NoError(t, err)
+	builder.RemoveAttribute("spec.from[*].default")
+	requirePathError(t, builder.Err(), "RemoveAttribute", "resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[*].default", hclbuilder.ErrAttributeNotFound)
+	require.Equal(t, before, builder.Build())
+}
@@ -0,0 +1,141 @@
+package hclbuilder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Clone returns a deep copy of this builder: the configuration, the provider
+// settings and the recorded errors, including those of the builders upserted
+// into it at the time of the call. Changes made to the clone are not visible
+// in this builder, and changes made to this builder or to the upserted
+// builders, including the errors they record later, are not visible in the
+// clone.
+//
+// Example: sharing a base builder between test steps.
+//
+//	step1 := base.Clone().Upsert(mesh)
+//	step2 := base.Clone().Upsert(mesh.Clone().AddAttribute("routing.zone_egress", true))
+func (b *Builder) Clone() *Builder {
+	clone := &Builder{
+		ProviderType:     b.ProviderType,
+		ProviderProperty: b.ProviderProperty,
+		errs:             b.collectErrors(make(map[*Builder]bool)),
+		copyOnWrite:      b.copyOnWrite,
+	}
+	if b.providerAliases != nil {
+		clone.providerAliases = make(map[ProviderType]string, len(b.providerAliases))
+		for provider, alias := range b.providerAliases {
+			clone.providerAliases[provider] = alias
+		}
+	}
+
+	// Parsing the output again copies the tokens, keeping the layout as-is
+	file, diags := hclwrite.ParseConfig(b.file.Bytes(), "<clone>", hcl.InitialPos)
+	if diags.HasErrors() {
+		clone.file = hclwrite.NewEmptyFile()
+		clone.addError("Clone", "", fmt.Errorf("parsing HCL: %s", diags.Error()))
+		return clone
+	}
+	clone.file = file
+	return clone
+}
+
+// CopyOnWrite returns a copy of this builder (see Clone) in copy-on-write
+// mode: every method that changes the builder leaves it untouched and returns
+// a modified copy instead, so that a builder can be used as an immutable
+// snapshot. Blocks of builders upserted into it are copied rather than shared.
+//
+// Mutators of a BlockBuilder obtained from a copy-on-write builder return a
+// BlockBuilder on a modified copy, available with BlockBuilder.Builder.
+//
+// Example:
+//
+//	base := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "").CopyOnWrite()
+//	withMesh := base.Upsert(mesh)              // base is unchanged
+//	renamed := withMesh.SetAttribute("resource.kong-mesh_mesh.default.name", "mesh-2")
+func (b *Builder) CopyOnWrite() *Builder {
+	clone := b.Clone()
+	clone.copyOnWrite = true
+	return clone
+}
+
+// Builder returns the builder this block belongs to. For copy-on-write
+// builders, this is the copy holding the changes made through this
+// BlockBuilder.
+func (bb *BlockBuilder) Builder() *Builder {
+	return bb.builder
+}
+
+// mutable returns the builder a mutation must be applied to: this builder, or
+// a copy of it in copy-on-write mode.
+func (b *Builder) mutable() *Builder {
+	if !b.copyOnWrite {
+		return b
+	}
+	return b.Clone()
+}
+
+// mutable returns the BlockBuilder a mutation must be applied to: this one, or
+// one referring to the same block in a copy of the builder in copy-on-write
+// mode.
+func (bb *BlockBuilder) mutable() *BlockBuilder {
+	if !bb.builder.copyOnWrite {
+		return bb
+	}
+
+	clone := &BlockBuilder{
+		builder: bb.builder.Clone(),
+		path:    bb.path,
+		err:     bb.err,
+	}
+	if bb.block != nil {
+		if indices, ok := blockIndices(bb.builder.file.Body(), bb.block); ok {
+			clone.block = blockAt(clone.builder.file.Body(), indices)
+		}
+	}
+	return clone
+}
+
+// blockIndices returns the position of target in body: the index of the
+// top-level block containing it, then the indices of the nested blocks down to it.
+func blockIndices(body *hclwrite.Body, target *hclwrite.Block) ([]int, bool) {
+	for i, block := range body.Blocks() {
+		if block == target {
+			return []int{i}, true
+		}
+		if indices, ok := blockIndices(block.Body(), target); ok {
+			return append([]int{i}, indices...), true
+		}
+	}
+	return nil, false
+}
+
+// blockAt returns the block at the position returned by blockIndices.
+func blockAt(body *hclwrite.Body, indices []int) *hclwrite.Block {
+	var block *hclwrite.Block
+	for _, i := range indices {
+		blocks := body.Blocks()
+		if i >= len(blocks) {
+			return nil
+		}
+		block = blocks[i]
+		body = block.Body()
+	}
+	return block
+}
+
+// copyBlock returns a deep copy of block, including the comments loaded with
+// it. Like Clone, it parses the block's tokens again to keep the layout as-is.
+func copyBlock(block *hclwrite.Block) (*hclwrite.Block, error) {
+	file, diags := hclwrite.ParseConfig(block.BuildTokens(nil).Bytes(), "<block>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing HCL: %s", diags.Error())
+	}
+	if len(file.Body().Blocks()) != 1 {
+		return nil, fmt.Errorf("parsing HCL: expected one block, got %d", len(file.Body().Blocks()))
+	}
+	return file.Body().Blocks()[0], nil
+}
@@ -0,0 +1,111 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test Clone() - the clone and the original are independent
+func TestClone(t *testing.T) {
+	builder := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "http://localhost:5681")
+	mesh, err := hclbuilder.FromFile(filepath.Join("testdata", "add-embed-and-mutate-mesh.input.tf"))
+	require.NoError(t, err)
+	builder.Upsert(mesh)
+	before := builder.Build()
+
+	clone := builder.Clone()
+	require.Equal(t, before, clone.Build())
+	require.Equal(t, builder.ProviderType, clone.ProviderType)
+
+	// Changes to the clone are not visible in the original
+	clone.Block("resource.kong-mesh_mesh.default").AddAttribute("routing.zone_egress", true)
+	clone.RemoveBlock("provider.kong-mesh")
+	require.Equal(t, before, builder.Build())
+
+	// Changes to the upserted builder are not visible in the clone
+	cloneBefore := clone.Build()
+	mesh.AddAttribute("name", `"renamed"`)
+	require.Equal(t, cloneBefore, clone.Build())
+	require.Contains(t, builder.Build(), `"renamed"`)
+
+	result, err := clone.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "clone.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test Clone() - errors, including those of upserted builders, are copied
+func TestClone_Errors(t *testing.T) {
+	builder := hclbuilder.New()
+	mesh, err := hclbuilder.FromFile(filepath.Join("testdata", "add-embed-and-mutate-mesh.input.tf"))
+	require.NoError(t, err)
+	builder.Upsert(mesh)
+	builder.RemoveBlock("resource.kong-mesh_mesh.missing")
+	mesh.RemoveBlock("resource.kong-mesh_mesh.earlier")
+
+	clone := builder.Clone()
+	requirePathError(t, clone.Err(), "RemoveBlock", "resource.kong-mesh_mesh.missing", hclbuilder.ErrBlockNotFound)
+	require.Contains(t, clone.Err().Error(), "resource.kong-mesh_mesh.earlier")
+
+	// New errors are only recorded on the builder the operation was made on
+	clone.RemoveBlock("resource.kong-mesh_mesh.other")
+	require.NotContains(t, builder.Err().Error(), "resource.kong-mesh_mesh.other")
+
+	// Errors recorded later by upserted builders are not visible in the clone
+	mesh.RemoveBlock("resource.kong-mesh_mesh.later")
+	require.Contains(t, builder.Err().Error(), "resource.kong-mesh_mesh.later")
+	require.NotContains(t, clone.Err().Error(), "resource.kong-mesh_mesh.later")
+
+	// Remove works on the clone, matching blocks by identity
+	clone.Remove(mesh)
+	require.NotContains(t, clone.Build(), "kong-mesh_mesh")
+	require.Contains(t, builder.Build(), "kong-mesh_mesh")
+}
+
+// Test CopyOnWrite() - mutators return modified copies
+func TestCopyOnWrite(t *testing.T) {
+	base := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "http://localhost:5681").CopyOnWrite()
+	mesh, err := hclbuilder.FromFile(filepath.Join("testdata", "add-embed-and-mutate-mesh.input.tf"))
+	require.NoError(t, err)
+	baseBefore := base.Build()
+
+	withMesh := base.Upsert(mesh)
+	require.Equal(t, baseBefore, base.Build())
+	require.NotEqual(t, baseBefore, withMesh.Build())
+	withMeshBefore := withMesh.Build()
+
+	// Upserted blocks are copied, not shared
+	mesh.AddAttribute("name", `"renamed"`)
+	require.Equal(t, withMeshBefore, withMesh.Build())
+
+	renamed := withMesh.SetAttribute("resource.kong-mesh_mesh.default.name", "mesh-2")
+	require.Equal(t, withMeshBefore, withMesh.Build())
+
+	// Block mutators return a BlockBuilder on a modified copy
+	block := renamed.Block("resource.kong-mesh_mesh.default")
+	egress := block.AddAttribute("routing.zone_egress", true)
+	require.Equal(t, renamed.Build(), block.Builder().Build())
+	require.NotSame(t, renamed, egress.Builder())
+	final := egress.RemoveAttribute("type").Builder()
+
+	withoutProvider := final.RemoveBlock("provider.kong-mesh")
+	require.Contains(t, final.Build(), `provider "kong-mesh"`)
+
+	// Builder-level mutators delegating to the first block are copy-on-write too
+	described := withoutProvider.AddAttribute("description", `"Default mesh"`)
+	require.NotContains(t, withoutProvider.Build(), "Default mesh")
+
+	result, err := described.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "copy-on-write.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	// Errors are recorded on the returned copy
+	failed := final.RemoveAttribute("missing")
+	require.NoError(t, final.Err())
+	require.ErrorIs(t, failed.Err(), hclbuilder.ErrAttributeNotFound)
+}
@@ -0,0 +1,167 @@
+package hclbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// WithComment sets the comment above the first block in this builder (see
+// BlockBuilder.WithComment).
+func (b *Builder) WithComment(comment string) *Builder {
+	return b.firstBlock().WithComment(comment).builder
+}
+
+// WithAttributeComment sets the comment above an attribute of the first block
+// in this builder (see BlockBuilder.WithAttributeComment).
+func (b *Builder) WithAttributeComment(name, comment string) *Builder {
+	return b.firstBlock().WithAttributeComment(name, comment).builder
+}
+
+// WithComment sets the comment above this block, replacing the comment
+// directly above it. Each line of comment is written as a # comment, and an
+// empty comment removes the existing one. A comment loaded with the block is
+// part of it, so builders the block is shared with (see Upsert) see the new
+// comment, like any other change to the block.
+// Example: builder.Block("resource.kong-mesh_mesh.default").WithComment("Mesh shared by every zone") writes
+//
+//	# Mesh shared by every zone
+//	resource "kong-mesh_mesh" "default" {
+func (bb *BlockBuilder) WithComment(comment string) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("WithComment", "")
+		return bb
+	}
+
+	body := bb.builder.file.Body()
+	indices, ok := blockIndices(body, bb.block)
+	if !ok {
+		bb.notFound("WithComment", "")
+		return bb
+	}
+	if len(indices) > 1 {
+		body = blockAt(body, indices[:len(indices)-1]).Body()
+	}
+
+	setComment(body, comment, func(item bodyItem) bool {
+		return item.block == bb.block
+	})
+	return bb
+}
+
+// WithAttributeComment sets the comment above the attribute with the given
+// name in this block, replacing the comment directly above it (see
+// WithComment). Only attributes of the block itself can have a comment, not
+// the fields of their values.
+// Example: builder.Block("resource.kong-mesh_mesh.default").WithAttributeComment("skip_creating_initial_policies", "Policies are managed below")
+func (bb *BlockBuilder) WithAttributeComment(name, comment string) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("WithAttributeComment", name)
+		return bb
+	}
+	if !hclsyntax.ValidIdentifier(name) {
+		bb.addError("WithAttributeComment", name, fmt.Errorf("%w: %q is not an attribute name", ErrInvalidPath, name))
+		return bb
+	}
+
+	body := bb.block.Body()
+	if body.GetAttribute(name) == nil {
+		bb.addError("WithAttributeComment", name, ErrAttributeNotFound)
+		return bb
+	}
+
+	setComment(body, comment, func(item bodyItem) bool {
+		return item.attribute != nil && item.name == name
+	})
+	return bb
+}
+
+// setComment replaces the comment directly above the item of body that match
+// reports with comment. The comments that are not separated from the item by
+// a blank line are replaced, whether they were loaded with it or written
+// before it. Comments loaded with a block are edited in place, since they are
+// part of the block's tokens.
+func setComment(body *hclwrite.Body, comment string, match func(item bodyItem) bool) {
+	// Unstructured tokens are held back until the next item, since the
+	// comments they end with are replaced if the next item is the target
+	var pending hclwrite.Tokens
+	flush := func(tokens hclwrite.Tokens) {
+		if len(tokens) > 0 {
+			body.AppendUnstructuredTokens(tokens)
+		}
+		pending = nil
+	}
+	rebuildBody(body, func(item bodyItem) {
+		if item.block == nil && item.attribute == nil {
+			pending = append(pending, item.tokens...)
+			return
+		}
+
+		if !match(item) {
+			flush(pending)
+			appendItem(body, item)
+			return
+		}
+
+		i := len(pending)
+		for i > 0 && pending[i-1].Type == hclsyntax.TokenComment {
+			i--
+		}
+		flush(pending[:i])
+
+		if item.block != nil {
+			if lead := leadComments(item.tokens); len(lead) > 0 {
+				replaceLeadComments(lead, comment)
+			} else {
+				flush(commentLines(comment))
+			}
+			body.AppendBlock(item.block)
+			return
+		}
+		flush(commentLines(comment))
+		appendAttribute(body, item)
+	})
+	flush(pending)
+}
+
+// replaceLeadComments replaces the comment tokens a block starts with by
+// comment: the first token holds every line of it, indented like the
+// original, and the other tokens are turned into empty newlines, which the
+// formatter treats as line breaks rather than as text before the block.
+func replaceLeadComments(lead hclwrite.Tokens, comment string) {
+	var text []byte
+	for i, line := range commentLines(comment) {
+		if i > 0 {
+			text = append(text, strings.Repeat(" ", lead[0].SpacesBefore)...)
+		}
+		text = append(text, line.Bytes...)
+	}
+	if len(text) > 0 {
+		lead[0].Bytes = text
+		lead = lead[1:]
+	}
+	for _, token := range lead {
+		token.Type = hclsyntax.TokenNewline
+		token.Bytes = nil
+		token.SpacesBefore = 0
+	}
+}
+
+// commentLines returns the tokens of a # comment for each line of comment.
+func commentLines(comment string) hclwrite.Tokens {
+	comment = strings.TrimRight(comment, "\n")
+	if comment == "" {
+		return nil
+	}
+
+	var tokens hclwrite.Tokens
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimRight("# "+line, " ")
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComment, Bytes: []byte(line + "\n")})
+	}
+	return tokens
+}
@@ -0,0 +1,186 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test WithComment() and WithAttributeComment() - comments on generated blocks
+func TestWithComment(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh", "name": "default"}).
+		WithComment("Mesh shared by every zone.\nCreated before the policies.").
+		WithAttributeComment("name", "Name of the mesh")
+	builder.Block("resource.kong-mesh_mesh.default").
+		Lifecycle(hclbuilder.Lifecycle{PreventDestroy: true}).
+		Block("lifecycle").WithComment("Deleting the mesh deletes its policies")
+	builder.SetBlock("variable.zone", map[string]any{"type": hclbuilder.Raw("string")}).
+		Block("variable.zone").WithComment("Zone of the control plane")
+
+	// Setting a comment again replaces it
+	builder.Block("variable.zone").WithComment("Name of the zone")
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "with-comment.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	// Comments survive a round trip
+	reloaded, err := hclbuilder.FromString(result)
+	require.NoError(t, err)
+	require.Equal(t, result, reloaded.Build())
+}
+
+// Test WithComment() - comments of a loaded file are replaced or removed
+func TestWithComment_Loaded(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "comments.input.tf"))
+	require.NoError(t, err)
+
+	mesh := builder.Block("resource.kong-mesh_mesh.default")
+	mesh.WithComment("Mesh of the zone").
+		WithAttributeComment("type", "").
+		WithAttributeComment("name", "Must match the zone configuration")
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").WithComment("")
+	require.NoError(t, builder.Err())
+
+	result := builder.Build()
+	require.Contains(t, result, "# Example configuration for a zone\n\n# Mesh of the zone\nresource")
+	require.NotContains(t, result, "Mesh shared by the policies below")
+	require.NotContains(t, result, "Resource type")
+	require.Contains(t, result, `type = "Mesh" # must be Mesh`)
+	require.Contains(t, result, "  # Must match the zone configuration\n  name = \"default\"")
+	require.NotContains(t, result, "Generated policy")
+
+	// The output parses to the same configuration
+	reloaded, err := hclbuilder.FromString(result)
+	require.NoError(t, err)
+	require.Equal(t, result, reloaded.Build())
+}
+
+// Test comments of a loaded file are kept through mutations
+func TestComments_Preserved(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "comments.input.tf"))
+	require.NoError(t, err)
+
+	mesh := builder.Block("resource.kong-mesh_mesh.default")
+	mesh.AddAttribute("type", `"Mesh"`).
+		AddAttribute("routing.default_forbid_mesh_external_service_access", true).
+		SetForEach([]string{"eu", "us"}).
+		Lifecycle(hclbuilder.Lifecycle{PreventDestroy: true, CreateBeforeDestroy: true})
+	builder.SetBlock("resource.kong-mesh_mesh_traffic_permission.allow_all", map[string]any{
+		"type": "MeshTrafficPermission",
+		"name": "allow-all",
+		"mesh": hclbuilder.Ref("kong-mesh_mesh.default.name"),
+	}).Block("resource.kong-mesh_mesh_traffic_permission.allow_all").SetCount(1)
+
+	result, err := builder.BuildWith(hclbuilder.BuildOptions{Format: true, SortAttributes: true, TrailingNewline: true})
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "comments.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	reloaded, err := hclbuilder.FromString(result)
+	require.NoError(t, err)
+	require.Equal(t, result, reloaded.Build())
+}
+
+// Test comments of a loaded file are kept by DependsOnAddress() and Lifecycle()
+func TestComments_DependenciesAndLifecycle(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "comments-dependencies-lifecycle.input.tf"))
+	require.NoError(t, err)
+
+	builder.DependsOnAddress("data.konnect_mesh_control_plane.cp").
+		RemoveDependsOnAddress("module.networking").
+		Lifecycle(hclbuilder.Lifecycle{PreventDestroy: true, IgnoreChanges: []string{"labels", "spec"}})
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "comments-dependencies-lifecycle.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test WithComment() - the comment loaded with a block shared with another builder is replaced in both
+func TestWithComment_Shared(t *testing.T) {
+	other, err := hclbuilder.FromFile(filepath.Join("testdata", "comments-dependencies-lifecycle.input.tf"))
+	require.NoError(t, err)
+
+	builder := hclbuilder.New().Upsert(other)
+	builder.WithComment("Replaced").
+		Block("resource.kong-mesh_mesh_traffic_permission.allow_all").
+		Block("lifecycle").
+		WithComment("Also replaced")
+
+	require.NoError(t, builder.Err())
+	require.Contains(t, builder.Build(), "# Replaced\nresource")
+	require.Contains(t, builder.Build(), "  # Also replaced\n  lifecycle {")
+	require.NotContains(t, builder.Build(), "# Allows all traffic")
+	require.Contains(t, other.Build(), "# Replaced\nresource")
+	require.Contains(t, other.Build(), "  # Also replaced\n  lifecycle {")
+
+	// Removing the comment keeps the layout
+	builder.WithComment("")
+	require.True(t, strings.HasPrefix(builder.Build(), "resource"))
+	require.Contains(t, other.Build(), "# Policies of the zone\n\nresource")
+}
+
+// Test WithComment() - BlockBuilders and builders sharing the block keep editing it
+func TestWithComment_EarlierHandle(t *testing.T) {
+	other, err := hclbuilder.FromFile(filepath.Join("testdata", "comments-dependencies-lifecycle.input.tf"))
+	require.NoError(t, err)
+	builder := hclbuilder.New().Upsert(other)
+
+	policy := builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all")
+	lifecycle := policy.Block("lifecycle")
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").WithComment("Replaced")
+	policy.Block("lifecycle").WithComment("Also replaced")
+
+	policy.AddAttribute("name", `"renamed"`)
+	lifecycle.AddAttribute("prevent_destroy", "true")
+	other.AddAttribute("description", `"shared"`)
+
+	require.NoError(t, builder.Err())
+	result := builder.Build()
+	require.Contains(t, result, "# Replaced\nresource")
+	require.Contains(t, result, `name = "renamed"`)
+	require.Contains(t, result, "prevent_destroy")
+	require.Contains(t, result, `description = "shared"`)
+}
+
+// Test WithComment() and WithAttributeComment() - copy on write
+func TestWithComment_CopyOnWrite(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	base := builder.CopyOnWrite()
+
+	commented := base.WithComment("Mesh").WithAttributeComment("type", "Always Mesh")
+	require.NotContains(t, base.Build(), "#")
+	require.Contains(t, commented.Build(), "# Mesh\nresource")
+	require.Contains(t, commented.Build(), "  # Always Mesh\n  type = \"Mesh\"")
+}
+
+// Test WithComment() and WithAttributeComment() - errors
+func TestWithComment_Errors(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.WithComment("No block")
+	requirePathError(t, builder.Err(), "WithComment", "", hclbuilder.ErrBlockNotFound)
+
+	builder = hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.Block("resource.kong-mesh_mesh.missing").WithComment("Missing")
+	requirePathError(t, builder.Err(), "WithComment", "resource.kong-mesh_mesh.missing", hclbuilder.ErrBlockNotFound)
+
+	builder = hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.WithAttributeComment("name", "Missing")
+	requirePathError(t, builder.Err(), "WithAttributeComment", "resource.kong-mesh_mesh.default.name", hclbuilder.ErrAttributeNotFound)
+
+	builder = hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.WithAttributeComment("routing.mode", "Nested")
+	requirePathError(t, builder.Err(), "WithAttributeComment", "resource.kong-mesh_mesh.default.routing.mode", hclbuilder.ErrInvalidPath)
+	require.NotContains(t, builder.Build(), "#")
+}
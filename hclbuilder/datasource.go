@@ -0,0 +1,58 @@
+package hclbuilder
+
+import "fmt"
+
+// AddDataSource adds a data source, or replaces the one with the same type
+// and name, and returns a BlockBuilder for it: use its Ref method to refer to
+// its attributes, and pass it to DependsOn.
+// Example:
+//
+//	cp := builder.AddDataSource("konnect_mesh_control_plane", "existing", map[string]any{"id": hclbuilder.Ref("var.cp_id")})
+//	builder.SetAttribute("resource.konnect_mesh.default.cp_id", cp.Ref("id"))
+//	builder.Block("resource.konnect_mesh.default").DependsOn(cp)
+//
+// For copy-on-write builders, the returned BlockBuilder belongs to the
+// modified copy (see BlockBuilder.Builder).
+func (b *Builder) AddDataSource(dataType, dataName string, attributes map[string]any) *BlockBuilder {
+	path := JoinPath("data", dataType, dataName)
+	return b.SetBlock(path, attributes).Block(path)
+}
+
+// AddControlPlaneData adds a data source looking up an existing mesh control
+// plane by id (for Konnect providers). id is a string or an Expression.
+// Example: builder.AddControlPlaneData("existing", hclbuilder.Ref("var.cp_id")) writes
+//
+//	data "konnect_mesh_control_plane" "existing" {
+//	  id = var.cp_id
+//	}
+func (b *Builder) AddControlPlaneData(dataName string, id any) *BlockBuilder {
+	attrs := map[string]any{"id": id}
+	b.addProviderRef(attrs)
+	return b.AddDataSource(fmt.Sprintf("%s_mesh_control_plane", b.ProviderProperty), dataName, attrs)
+}
+
+// AddMeshData adds a data source looking up an existing mesh by name. name is
+// a string or an Expression.
+// Example: builder.AddMeshData("default", "default") writes
+//
+//	data "kong-mesh_mesh" "default" {
+//	  name = "default"
+//	}
+func (b *Builder) AddMeshData(dataName string, name any) *BlockBuilder {
+	attrs := map[string]any{"name": name}
+	b.addProviderRef(attrs)
+	return b.AddDataSource(fmt.Sprintf("%s_mesh", b.ProviderProperty), dataName, attrs)
+}
+
+// AddPolicyData adds a data source looking up an existing policy of the
+// given type (e.g. "mesh_traffic_permission") by mesh and name, which are
+// strings or Expressions.
+// Example: builder.AddPolicyData("mesh_traffic_permission", "allow_all", mesh.Ref("name"), "allow-all")
+func (b *Builder) AddPolicyData(policyType, dataName string, mesh, name any) *BlockBuilder {
+	attrs := map[string]any{
+		"mesh": mesh,
+		"name": name,
+	}
+	b.addProviderRef(attrs)
+	return b.AddDataSource(fmt.Sprintf("%s_%s", b.ProviderProperty, policyType), dataName, attrs)
+}
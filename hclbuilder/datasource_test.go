@@ -0,0 +1,70 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test AddControlPlaneData(), AddMeshData() and AddPolicyData() - references and dependencies
+func TestDataSources(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.ProviderProperty = hclbuilder.Konnect
+	builder.WithProviderOptions(hclbuilder.Konnect, hclbuilder.ProviderOptions{Alias: "eu"})
+
+	cp := builder.AddControlPlaneData("existing", hclbuilder.Ref("var.cp_id"))
+	require.Equal(t, "data.konnect_mesh_control_plane.existing", cp.Address())
+
+	builder.UseProvider(hclbuilder.Konnect, "")
+	mesh := builder.AddMeshData("default", "default")
+	policy := builder.AddPolicyData("mesh_traffic_permission", "allow_all", mesh.Ref("name"), "allow-all")
+
+	builder.SetBlock("resource.konnect_mesh.default", map[string]any{
+		"cp_id": cp.Ref("id"),
+		"name":  "mesh-2",
+	})
+	builder.Block("resource.konnect_mesh.default").DependsOn(cp).DependsOn(policy)
+	builder.AddOutput("mesh_name", mesh.Ref("name"))
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "data-sources.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test AddDataSource() - replacing a data source, and removing it from depends_on
+func TestAddDataSource(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"name": "default"})
+	data := builder.AddDataSource("kong-mesh_mesh", "other", map[string]any{"name": "other"})
+	builder.DependsOn(data)
+	require.Equal(t, []string{"data.kong-mesh_mesh.other"}, builder.Dependencies())
+
+	data = builder.AddDataSource("kong-mesh_mesh", "other", map[string]any{"name": "renamed"})
+	require.Len(t, builder.Blocks("data"), 1)
+	value, ok := data.GetAttribute("name")
+	require.True(t, ok)
+	require.Equal(t, "renamed", value)
+
+	builder.RemoveDependsOn(data)
+	require.Empty(t, builder.Dependencies())
+
+	// Only resources, data sources and modules can be depended on
+	builder.DependsOn(builder.Block("data.kong-mesh_mesh.missing"))
+	require.ErrorIs(t, builder.Err(), hclbuilder.ErrNotResource)
+}
+
+// Test AddDataSource() - copy-on-write builders return the data source of the copy
+func TestAddDataSource_CopyOnWrite(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.ProviderProperty = hclbuilder.KongMesh
+	base := builder.CopyOnWrite()
+
+	data := base.AddMeshData("default", "default")
+	require.Empty(t, base.Build())
+	require.Contains(t, data.Builder().Build(), `data "kong-mesh_mesh" "default"`)
+	require.True(t, data.Exists())
+}
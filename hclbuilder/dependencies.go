@@ -0,0 +1,389 @@
+package hclbuilder
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Addressable is a block that can be referenced by its Terraform address: a
+// Builder (its first block) or a BlockBuilder, such as the data sources
+// returned by AddDataSource.
+type Addressable interface {
+	Address() string
+}
+
+// Address returns the Terraform address of the first block in this builder,
+// as used in references and depends_on (see BlockBuilder.Address).
+func (b *Builder) Address() string {
+	return b.firstBlock().Address()
+}
+
+// Address returns the Terraform address of this block, as used in references
+// and depends_on:
+//
+//	resource "type" "name" -> type.name
+//	data "type" "name"     -> data.type.name
+//	module "name"          -> module.name
+//
+// Returns empty string if the block does not exist or is of another kind.
+func (bb *BlockBuilder) Address() string {
+	if bb.block == nil {
+		return ""
+	}
+
+	labels := bb.block.Labels()
+	switch {
+	case bb.block.Type() == "resource" && len(labels) == 2:
+		return labels[0] + "." + labels[1]
+	case bb.block.Type() == "data" && len(labels) == 2:
+		return "data." + labels[0] + "." + labels[1]
+	case bb.block.Type() == "module" && len(labels) == 1:
+		return "module." + labels[0]
+	default:
+		return ""
+	}
+}
+
+// DependsOn adds a depends_on attribute to the first block in this builder,
+// referencing the other builder's resource or the other block.
+// Example:
+//
+//	cp := hclbuilder.New()
+//	mesh := hclbuilder.New()
+//	mesh.DependsOn(cp)
+//
+// This will add: depends_on = [konnect_mesh_control_plane.my_meshcontrolplane]
+func (b *Builder) DependsOn(other Addressable) *Builder {
+	return b.firstBlock().DependsOn(other).builder
+}
+
+// DependsOnAddress adds addresses to the depends_on attribute of the first
+// block in this builder.
+// Example: builder.DependsOnAddress("module.networking", `konnect_mesh.m["a"]`)
+func (b *Builder) DependsOnAddress(addresses ...string) *Builder {
+	return b.firstBlock().DependsOnAddress(addresses...).builder
+}
+
+// RemoveDependsOn removes the other builder's block, or the other block, from
+// the depends_on attribute of the first block in this builder.
+func (b *Builder) RemoveDependsOn(other Addressable) *Builder {
+	return b.firstBlock().RemoveDependsOn(other).builder
+}
+
+// RemoveDependsOnAddress removes addresses from the depends_on attribute of
+// the first block in this builder.
+func (b *Builder) RemoveDependsOnAddress(addresses ...string) *Builder {
+	return b.firstBlock().RemoveDependsOnAddress(addresses...).builder
+}
+
+// SetDependsOn replaces the depends_on attribute of the first block in this
+// builder. Without addresses, depends_on is removed.
+func (b *Builder) SetDependsOn(addresses ...string) *Builder {
+	return b.firstBlock().SetDependsOn(addresses...).builder
+}
+
+// Dependencies returns the addresses in the depends_on attribute of the first
+// block in this builder.
+func (b *Builder) Dependencies() []string {
+	return b.firstBlock().Dependencies()
+}
+
+// DependsOn adds the other builder's block, or the other block (a resource,
+// data source or module), to the depends_on attribute of this block. Nothing
+// is added if it is already listed.
+func (bb *BlockBuilder) DependsOn(other Addressable) *BlockBuilder {
+	bb = bb.mutable()
+	address := other.Address()
+	if address == "" {
+		bb.addError("DependsOn", "depends_on", fmt.Errorf("%w: other is not a resource, data source or module block", ErrNotResource))
+		return bb
+	}
+	return bb.editDependencies("DependsOn", func(deps []string) ([]string, error) {
+		return addDependencies(deps, address), nil
+	})
+}
+
+// DependsOnAddress adds addresses to the depends_on attribute of this block.
+// Addresses can refer to resources, data sources, modules or instances of
+// them (e.g. "konnect_mesh.m[0]"). Addresses already listed are skipped.
+func (bb *BlockBuilder) DependsOnAddress(addresses ...string) *BlockBuilder {
+	bb = bb.mutable()
+	return bb.editDependencies("DependsOnAddress", func(deps []string) ([]string, error) {
+		normalized, err := normalizeAddresses(addresses)
+		if err != nil {
+			return nil, err
+		}
+		return addDependencies(deps, normalized...), nil
+	})
+}
+
+// RemoveDependsOn removes the other builder's block, or the other block, from
+// the depends_on attribute of this block. depends_on is removed when it
+// becomes empty.
+func (bb *BlockBuilder) RemoveDependsOn(other Addressable) *BlockBuilder {
+	bb = bb.mutable()
+	address := other.Address()
+	if address == "" {
+		bb.addError("RemoveDependsOn", "depends_on", fmt.Errorf("%w: other is not a resource, data source or module block", ErrNotResource))
+		return bb
+	}
+	return bb.editDependencies("RemoveDependsOn", func(deps []string) ([]string, error) {
+		return removeDependencies(deps, address)
+	})
+}
+
+// RemoveDependsOnAddress removes addresses from the depends_on attribute of
+// this block. depends_on is removed when it becomes empty.
+func (bb *BlockBuilder) RemoveDependsOnAddress(addresses ...string) *BlockBuilder {
+	bb = bb.mutable()
+	return bb.editDependencies("RemoveDependsOnAddress", func(deps []string) ([]string, error) {
+		normalized, err := normalizeAddresses(addresses)
+		if err != nil {
+			return nil, err
+		}
+		return removeDependencies(deps, normalized...)
+	})
+}
+
+// SetDependsOn replaces the depends_on attribute of this block. Without
+// addresses, depends_on is removed.
+func (bb *BlockBuilder) SetDependsOn(addresses ...string) *BlockBuilder {
+	bb = bb.mutable()
+	return bb.editDependencies("SetDependsOn", func([]string) ([]string, error) {
+		normalized, err := normalizeAddresses(addresses)
+		if err != nil {
+			return nil, err
+		}
+		return addDependencies(nil, normalized...), nil
+	})
+}
+
+// Dependencies returns the addresses in the depends_on attribute of this
+// block, normalized (e.g. legacy quoted addresses are unquoted). Returns nil
+// if the block does not exist, has no depends_on or it cannot be parsed.
+func (bb *BlockBuilder) Dependencies() []string {
+	if bb.block == nil {
+		return nil
+	}
+	deps, err := parseDependencies(bb.block.Body().GetAttribute("depends_on"))
+	if err != nil {
+		return nil
+	}
+	return deps
+}
+
+// editDependencies replaces the depends_on attribute of this block with the
+// result of edit, which receives the current dependencies. depends_on is
+// left untouched if the dependencies do not change, and otherwise edited
+// element by element so that the comments in the list are kept.
+func (bb *BlockBuilder) editDependencies(op string, edit func(deps []string) ([]string, error)) *BlockBuilder {
+	if bb.block == nil {
+		bb.notFound(op, "depends_on")
+		return bb
+	}
+
+	body := bb.block.Body()
+	deps, err := parseDependencies(body.GetAttribute("depends_on"))
+	if err != nil {
+		bb.addError(op, "depends_on", err)
+		return bb
+	}
+
+	updated, err := edit(deps)
+	if err != nil {
+		bb.addError(op, "depends_on", err)
+		return bb
+	}
+	if body.GetAttribute("depends_on") != nil && slices.Equal(deps, updated) {
+		return bb
+	}
+
+	if len(updated) == 0 {
+		body.RemoveAttribute("depends_on")
+		return bb
+	}
+
+	src := []byte("[" + strings.Join(updated, ", ") + "]")
+	if attr := body.GetAttribute("depends_on"); attr != nil {
+		src, err = spliceDependencies(exprSource(attr), deps, updated)
+		if err != nil {
+			bb.addError(op, "depends_on", err)
+			return bb
+		}
+	}
+	tokens, err := exprTokens(src)
+	if err != nil {
+		bb.addError(op, "depends_on", err)
+		return bb
+	}
+	body.SetAttributeRaw("depends_on", tokens)
+
+	return bb
+}
+
+// spliceDependencies returns the depends_on list src, holding deps, edited
+// to hold updated: the elements that are no longer listed are removed with
+// their comments, legacy quoted addresses are unquoted and the new addresses
+// are appended. The list is rewritten if updated reorders the dependencies.
+func spliceDependencies(src []byte, deps, updated []string) ([]byte, error) {
+	var kept []string
+	for _, dep := range deps {
+		if slices.Contains(updated, dep) {
+			kept = append(kept, dep)
+		}
+	}
+	if len(kept) > len(updated) || !slices.Equal(kept, updated[:len(kept)]) {
+		// Keep the list on several lines if it was
+		if bytes.Contains(src, []byte("\n")) {
+			return []byte("[\n" + strings.Join(updated, ",\n") + ",\n]"), nil
+		}
+		return []byte("[" + strings.Join(updated, ", ") + "]"), nil
+	}
+
+	// Edit one element at a time, parsing the list again after each edit so
+	// that separators are computed on the current list
+	for {
+		tuple, err := parseDependencyList(src)
+		if err != nil {
+			return nil, err
+		}
+		edited := false
+		for i := len(tuple.Exprs) - 1; i >= 0 && !edited; i-- {
+			elem := tuple.Exprs[i]
+			address, err := dependencyAddress(elem)
+			if err != nil {
+				return nil, err
+			}
+			switch rng := elem.Range(); {
+			case !slices.Contains(updated, address):
+				start, end := elementExtent(src, tuple, elem)
+				src, edited = splice(src, start, end, nil), true
+			case string(src[rng.Start.Byte:rng.End.Byte]) != address:
+				src, edited = splice(src, rng.Start.Byte, rng.End.Byte, []byte(address)), true
+			}
+		}
+		if !edited {
+			break
+		}
+	}
+
+	for _, address := range updated[len(kept):] {
+		var err error
+		if src, err = insertElement(-1, []byte(address))(src); err != nil {
+			return nil, err
+		}
+	}
+	return src, nil
+}
+
+// parseDependencies returns the normalized addresses listed in a depends_on
+// attribute. Both references and legacy quoted strings are accepted.
+func parseDependencies(attr *hclwrite.Attribute) ([]string, error) {
+	if attr == nil {
+		return nil, nil
+	}
+
+	tuple, err := parseDependencyList(exprSource(attr))
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]string, 0, len(tuple.Exprs))
+	for _, elem := range tuple.Exprs {
+		address, err := dependencyAddress(elem)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, address)
+	}
+	return deps, nil
+}
+
+// parseDependencyList parses the source of a depends_on attribute.
+func parseDependencyList(src []byte) (*hclsyntax.TupleConsExpr, error) {
+	expr, err := parseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return nil, fmt.Errorf("%w: depends_on is not a list", ErrInvalidExpression)
+	}
+	return tuple, nil
+}
+
+// dependencyAddress returns the normalized address of an element of a
+// depends_on list.
+func dependencyAddress(elem hclsyntax.Expression) (string, error) {
+	var traversal hcl.Traversal
+	switch elem := elem.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		traversal = elem.Traversal
+	case *hclsyntax.TemplateExpr:
+		// Legacy syntax: depends_on = ["konnect_mesh.m"]
+		val, diags := elem.Value(nil)
+		if diags.HasErrors() || !elem.IsStringLiteral() {
+			return "", fmt.Errorf("%w: depends_on contains a template", ErrInvalidExpression)
+		}
+		return normalizeAddress(val.AsString())
+	default:
+		return "", fmt.Errorf("%w: depends_on contains an expression that is not a reference", ErrInvalidExpression)
+	}
+	return string(hclwrite.TokensForTraversal(traversal).Bytes()), nil
+}
+
+// normalizeAddress parses an address and returns it in canonical form, so
+// that e.g. `konnect_mesh.m[ "a" ]` and `konnect_mesh.m["a"]` are deduplicated.
+func normalizeAddress(address string) (string, error) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "<address>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("%w: %q is not an address: %s", ErrInvalidExpression, address, diags.Error())
+	}
+	return string(hclwrite.TokensForTraversal(traversal).Bytes()), nil
+}
+
+func normalizeAddresses(addresses []string) ([]string, error) {
+	normalized := make([]string, len(addresses))
+	for i, address := range addresses {
+		var err error
+		if normalized[i], err = normalizeAddress(address); err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
+
+// addDependencies appends the addresses that are not yet in deps.
+func addDependencies(deps []string, addresses ...string) []string {
+	result := append([]string(nil), deps...)
+	for _, address := range addresses {
+		if !slices.Contains(result, address) {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+// removeDependencies removes addresses from deps. Addresses that are not
+// listed are reported with ErrAttributeNotFound.
+func removeDependencies(deps []string, addresses ...string) ([]string, error) {
+	for _, address := range addresses {
+		if !slices.Contains(deps, address) {
+			return nil, fmt.Errorf("%w: %s is not in depends_on", ErrAttributeNotFound, address)
+		}
+	}
+
+	var result []string
+	for _, dep := range deps {
+		if !slices.Contains(addresses, dep) {
+			result = append(result, dep)
+		}
+	}
+	return result, nil
+}
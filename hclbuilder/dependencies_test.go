@@ -0,0 +1,125 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test Address() - resources, data sources and modules
+func TestAddress(t *testing.T) {
+	builder, err := hclbuilder.FromString(`
+resource "konnect_mesh" "m" {}
+data "konnect_mesh_control_plane" "cp" {}
+module "networking" {}
+variable "name" {}
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, "konnect_mesh.m", builder.Address())
+	require.Equal(t, "data.konnect_mesh_control_plane.cp", builder.Block("data.konnect_mesh_control_plane.cp").Address())
+	require.Equal(t, "module.networking", builder.Block("module.networking").Address())
+	require.Empty(t, builder.Block("variable.name").Address())
+	require.Empty(t, builder.Block("resource.missing.block").Address())
+}
+
+// Test Dependencies() - references, legacy quoted strings, instances and modules are parsed
+func TestDependencies(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "depends-on-parse.input.tf"))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"konnect_mesh_control_plane.cp",
+		"konnect_mesh.m[0]",
+		`konnect_mesh.m["a"]`,
+		"module.networking",
+		"data.konnect_mesh_control_plane.existing",
+		"konnect_mesh_control_plane.legacy",
+	}, builder.Dependencies())
+}
+
+// Test DependsOnAddress(), RemoveDependsOn() and RemoveDependsOnAddress() - edits keep the other dependencies
+func TestDependsOn_Edit(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "depends-on-parse.input.tf"))
+	require.NoError(t, err)
+
+	cp, err := hclbuilder.FromString(`resource "konnect_mesh_control_plane" "cp" {}`)
+	require.NoError(t, err)
+	existing, err := hclbuilder.FromString(`data "konnect_mesh_control_plane" "existing" {}`)
+	require.NoError(t, err)
+
+	builder.DependsOnAddress(`konnect_mesh.m[ "a" ]`, "module.networking", "konnect_mesh.m[1]").
+		DependsOn(existing).
+		RemoveDependsOn(cp).
+		RemoveDependsOnAddress("konnect_mesh.m[0]", "konnect_mesh_control_plane.legacy")
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "depends-on-edit.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test DependsOnAddress() and RemoveDependsOnAddress() - comments in depends_on are kept
+func TestDependsOn_Comments(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "depends-on-comments.input.tf"))
+	require.NoError(t, err)
+
+	builder.DependsOnAddress("data.konnect_mesh_control_plane.existing").
+		RemoveDependsOnAddress("konnect_mesh_control_plane.legacy")
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "depends-on-comments.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	// Appended addresses go after the line comment of the last element
+	builder.DependsOnAddress("module.zones")
+	require.Contains(t, builder.Build(), "data.konnect_mesh_control_plane.existing,\n    module.zones,\n")
+	builder, err = hclbuilder.FromString(`resource "kong-mesh_mesh" "default" {
+  depends_on = [
+    module.networking # inline
+  ]
+}
+`)
+	require.NoError(t, err)
+	builder.DependsOnAddress("module.zones")
+	require.Contains(t, builder.Build(), "module.networking, # inline\n    module.zones\n")
+	require.Equal(t, []string{"module.networking", "module.zones"}, builder.Dependencies())
+}
+
+// Test SetDependsOn() - replaces depends_on, or removes it without addresses
+func TestSetDependsOn(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "depends-on-parse.input.tf"))
+	require.NoError(t, err)
+
+	builder.SetDependsOn("module.networking", "module.networking")
+	require.Equal(t, []string{"module.networking"}, builder.Dependencies())
+
+	builder.SetDependsOn()
+	require.Nil(t, builder.Dependencies())
+	require.NotContains(t, builder.Build(), "depends_on")
+	require.NoError(t, builder.Err())
+}
+
+// Test DependsOn() - invalid addresses and missing dependencies are recorded
+func TestDependsOn_Errors(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "depends-on-parse.input.tf"))
+	require.NoError(t, err)
+	before := builder.Build()
+
+	variable, err := hclbuilder.FromString(`variable "name" {}`)
+	require.NoError(t, err)
+
+	builder.DependsOnAddress("konnect_mesh.m[*]")
+	builder.RemoveDependsOnAddress("konnect_mesh.other")
+	builder.DependsOn(variable)
+
+	require.Equal(t, before, builder.Build())
+	err = builder.Err()
+	requirePathError(t, err, "DependsOnAddress", "resource.kong-mesh_mesh.default.depends_on", hclbuilder.ErrInvalidExpression)
+	require.ErrorIs(t, err, hclbuilder.ErrAttributeNotFound)
+	require.ErrorIs(t, err, hclbuilder.ErrNotResource)
+}
@@ -0,0 +1,358 @@
+package hclbuilder
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	// ChangeAdded is a block or attribute only present in the second builder.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is a block or attribute only present in the first builder.
+	ChangeRemoved
+	// ChangeModified is an attribute whose value differs between the builders.
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// Change is a difference between two builders found by Diff.
+type Change struct {
+	Kind ChangeKind
+	// Path of the block or attribute, in the format accepted by Block and
+	// GetAttribute, e.g. "resource.kong-mesh_mesh.default.routing.zone_egress".
+	// Repeated nested blocks are told apart with an index: "dynamic.rule[1]".
+	Path string
+	// Block reports whether the change is a whole block being added or removed.
+	Block bool
+	// Old and New are the values of the attribute in normalized HCL syntax,
+	// empty for blocks and for the side where the attribute is missing.
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	prefix := map[ChangeKind]string{ChangeAdded: "+", ChangeRemoved: "-", ChangeModified: "~"}[c.Kind]
+	switch {
+	case c.Block:
+		return fmt.Sprintf("%s %s {}", prefix, c.Path)
+	case c.Kind == ChangeAdded:
+		return fmt.Sprintf("%s %s = %s", prefix, c.Path, c.New)
+	case c.Kind == ChangeRemoved:
+		return fmt.Sprintf("%s %s = %s", prefix, c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s %s = %s -> %s", prefix, c.Path, c.Old, c.New)
+	}
+}
+
+// Changes is the list of differences returned by Diff.
+type Changes []Change
+
+// String prints one change per line, ready to be used in test failure
+// messages:
+//
+//	~ resource.kong-mesh_mesh.default.name = "mesh-1" -> "mesh-2"
+//	+ resource.kong-mesh_mesh.default.routing.zone_egress = true
+//	- resource.kong-mesh_mesh_traffic_permission.old {}
+//
+// Returns empty string if there are no changes.
+func (c Changes) String() string {
+	lines := make([]string, len(c))
+	for i, change := range c {
+		lines[i] = change.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff returns the semantic differences between two builders: blocks and
+// attributes added, removed or changed from a to b. Formatting, comments and
+// the order of attributes and object keys are ignored. Blocks are matched by
+// identity (see MergeStrategy), objects are compared key by key and lists
+// element by element, so changes are reported at the deepest path.
+func Diff(a, b *Builder) Changes {
+	var changes Changes
+	diffBodies(&changes, "", builderBody(a), builderBody(b))
+	return changes
+}
+
+// builderBody returns the body of a builder, or an empty body.
+func builderBody(b *Builder) *hclwrite.Body {
+	if b == nil || b.file == nil {
+		return hclwrite.NewEmptyFile().Body()
+	}
+	return b.file.Body()
+}
+
+// diffBodies appends the changes between two bodies, whose paths are
+// prefixed with path.
+func diffBodies(changes *Changes, path string, a, b *hclwrite.Body) {
+	names := map[string]bool{}
+	for name := range a.Attributes() {
+		names[name] = true
+	}
+	for name := range b.Attributes() {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		attrPath := joinChildPath(path, JoinPath(name))
+		aAttr, bAttr := a.GetAttribute(name), b.GetAttribute(name)
+		switch {
+		case bAttr == nil:
+			*changes = append(*changes, Change{Kind: ChangeRemoved, Path: attrPath, Old: normalizeExpr(exprSource(aAttr))})
+		case aAttr == nil:
+			*changes = append(*changes, Change{Kind: ChangeAdded, Path: attrPath, New: normalizeExpr(exprSource(bAttr))})
+		default:
+			diffExprs(changes, attrPath, exprSource(aAttr), exprSource(bAttr))
+		}
+	}
+
+	aBlocks, bBlocks := groupBlocks(a), groupBlocks(b)
+	for _, key := range aBlocks.keys {
+		diffBlockGroup(changes, path, aBlocks.blocks[key], bBlocks.blocks[key])
+	}
+	for _, key := range bBlocks.keys {
+		if _, ok := aBlocks.blocks[key]; !ok {
+			diffBlockGroup(changes, path, nil, bBlocks.blocks[key])
+		}
+	}
+}
+
+// blockGroups are the blocks of a body grouped by identity, in source order.
+type blockGroups struct {
+	keys   []string
+	blocks map[string][]*hclwrite.Block
+}
+
+func groupBlocks(body *hclwrite.Body) blockGroups {
+	groups := blockGroups{blocks: map[string][]*hclwrite.Block{}}
+	for _, block := range body.Blocks() {
+		key := blockKey(block)
+		if _, ok := groups.blocks[key]; !ok {
+			groups.keys = append(groups.keys, key)
+		}
+		groups.blocks[key] = append(groups.blocks[key], block)
+	}
+	return groups
+}
+
+// diffBlockGroup compares blocks with the same identity, in order.
+func diffBlockGroup(changes *Changes, path string, a, b []*hclwrite.Block) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var block *hclwrite.Block
+		if i < len(a) {
+			block = a[i]
+		} else {
+			block = b[i]
+		}
+		blockPath := joinChildPath(path, JoinPath(append([]string{block.Type()}, block.Labels()...)...))
+		if len(a) > 1 || len(b) > 1 {
+			blockPath += fmt.Sprintf("[%d]", i)
+		}
+
+		switch {
+		case i >= len(b):
+			*changes = append(*changes, Change{Kind: ChangeRemoved, Path: blockPath, Block: true})
+		case i >= len(a):
+			*changes = append(*changes, Change{Kind: ChangeAdded, Path: blockPath, Block: true})
+		default:
+			diffBodies(changes, blockPath, a[i].Body(), b[i].Body())
+		}
+	}
+}
+
+// diffExprs appends the changes between two expressions. Object constructors
+// with static keys are compared key by key and tuples element by element.
+func diffExprs(changes *Changes, path string, a, b []byte) {
+	aExpr, aErr := parseExpr(a)
+	bExpr, bErr := parseExpr(b)
+	if aErr == nil && bErr == nil {
+		aObj, aIsObj := aExpr.(*hclsyntax.ObjectConsExpr)
+		bObj, bIsObj := bExpr.(*hclsyntax.ObjectConsExpr)
+		if aIsObj && bIsObj {
+			if aItems, ok := objectItems(aObj, a); ok {
+				if bItems, ok := objectItems(bObj, b); ok {
+					diffObjects(changes, path, aItems, bItems)
+					return
+				}
+			}
+		}
+
+		aTuple, aIsTuple := aExpr.(*hclsyntax.TupleConsExpr)
+		bTuple, bIsTuple := bExpr.(*hclsyntax.TupleConsExpr)
+		if aIsTuple && bIsTuple {
+			for i := 0; i < len(aTuple.Exprs) || i < len(bTuple.Exprs); i++ {
+				elemPath := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(bTuple.Exprs):
+					*changes = append(*changes, Change{Kind: ChangeRemoved, Path: elemPath, Old: normalizeExpr(rangeSource(a, aTuple.Exprs[i].Range()))})
+				case i >= len(aTuple.Exprs):
+					*changes = append(*changes, Change{Kind: ChangeAdded, Path: elemPath, New: normalizeExpr(rangeSource(b, bTuple.Exprs[i].Range()))})
+				default:
+					diffExprs(changes, elemPath, rangeSource(a, aTuple.Exprs[i].Range()), rangeSource(b, bTuple.Exprs[i].Range()))
+				}
+			}
+			return
+		}
+	}
+
+	if old, updated := normalizeExpr(a), normalizeExpr(b); old != updated {
+		*changes = append(*changes, Change{Kind: ChangeModified, Path: path, Old: old, New: updated})
+	}
+}
+
+// objectItems returns the source of the values of an object constructor by
+// key, or false if a key is not static.
+func objectItems(obj *hclsyntax.ObjectConsExpr, src []byte) (map[string][]byte, bool) {
+	items := make(map[string][]byte, len(obj.Items))
+	for _, item := range obj.Items {
+		key, ok := objectItemKey(item)
+		if !ok {
+			return nil, false
+		}
+		items[key] = rangeSource(src, item.ValueExpr.Range())
+	}
+	return items, true
+}
+
+// diffObjects compares the items of two objects, in key order.
+func diffObjects(changes *Changes, path string, a, b map[string][]byte) {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := joinChildPath(path, JoinPath(key))
+		aValue, inA := a[key]
+		bValue, inB := b[key]
+		switch {
+		case !inB:
+			*changes = append(*changes, Change{Kind: ChangeRemoved, Path: keyPath, Old: normalizeExpr(aValue)})
+		case !inA:
+			*changes = append(*changes, Change{Kind: ChangeAdded, Path: keyPath, New: normalizeExpr(bValue)})
+		default:
+			diffExprs(changes, keyPath, aValue, bValue)
+		}
+	}
+}
+
+func joinChildPath(path, child string) string {
+	if path == "" {
+		return child
+	}
+	return path + "." + child
+}
+
+func rangeSource(src []byte, rng hcl.Range) []byte {
+	return src[rng.Start.Byte:rng.End.Byte]
+}
+
+// normalizeExpr returns an expression in normalized HCL syntax, on a single
+// line and without comments, so that expressions differing only in layout
+// compare equal. Static values are written in canonical form, so that e.g. a
+// heredoc and a quoted string with the same content compare equal too.
+func normalizeExpr(src []byte) string {
+	expr, err := parseExpr(src)
+	if err != nil {
+		return string(bytes.TrimSpace(src))
+	}
+	if _, isObj := expr.(*hclsyntax.ObjectConsExpr); !isObj {
+		if _, isTuple := expr.(*hclsyntax.TupleConsExpr); !isTuple {
+			if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+				return string(hclwrite.TokensForValue(val).Bytes())
+			}
+		}
+	}
+
+	tokens, diags := hclsyntax.LexExpression(src, "<expr>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return string(bytes.TrimSpace(src))
+	}
+
+	// Newlines separate object items: turn them into commas, and drop them
+	// everywhere else along with comments and trailing commas. Tokens are
+	// separated by a space for hclwrite.Format to re-space them, except in
+	// templates where spaces would be part of the string.
+	var kept hclwrite.Tokens
+	var nesting []hclsyntax.TokenType
+	for i, token := range tokens {
+		inTemplate := len(nesting) > 0 && (nesting[len(nesting)-1] == hclsyntax.TokenOQuote || nesting[len(nesting)-1] == hclsyntax.TokenOHeredoc)
+		switch token.Type {
+		case hclsyntax.TokenComment, hclsyntax.TokenEOF:
+			continue
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen, hclsyntax.TokenOQuote, hclsyntax.TokenOHeredoc,
+			hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			nesting = append(nesting, token.Type)
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen, hclsyntax.TokenCQuote, hclsyntax.TokenCHeredoc,
+			hclsyntax.TokenTemplateSeqEnd:
+			if len(nesting) > 0 {
+				nesting = nesting[:len(nesting)-1]
+			}
+		case hclsyntax.TokenNewline:
+			inObject := len(nesting) > 0 && nesting[len(nesting)-1] == hclsyntax.TokenOBrace
+			if !inObject || len(kept) == 0 || isSeparatorAfter(kept[len(kept)-1].Type) || isSeparatorBefore(nextType(tokens, i)) {
+				continue
+			}
+			token = hclsyntax.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")}
+		case hclsyntax.TokenComma:
+			if next := nextType(tokens, i); next == hclsyntax.TokenCBrace || next == hclsyntax.TokenCBrack {
+				continue
+			}
+		}
+
+		spaces := 1
+		if inTemplate || len(kept) == 0 {
+			spaces = 0
+		}
+		kept = append(kept, &hclwrite.Token{Type: token.Type, Bytes: token.Bytes, SpacesBefore: spaces})
+	}
+	return string(bytes.TrimSpace(hclwrite.Format(kept.Bytes())))
+}
+
+// nextType returns the type of the next token that is not a newline or a comment.
+func nextType(tokens hclsyntax.Tokens, i int) hclsyntax.TokenType {
+	for _, token := range tokens[i+1:] {
+		if token.Type != hclsyntax.TokenNewline && token.Type != hclsyntax.TokenComment {
+			return token.Type
+		}
+	}
+	return hclsyntax.TokenEOF
+}
+
+func isSeparatorAfter(t hclsyntax.TokenType) bool {
+	return t == hclsyntax.TokenOBrace || t == hclsyntax.TokenComma
+}
+
+func isSeparatorBefore(t hclsyntax.TokenType) bool {
+	return t == hclsyntax.TokenCBrace || t == hclsyntax.TokenComma || t == hclsyntax.TokenEOF
+}
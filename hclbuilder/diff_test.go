@@ -0,0 +1,190 @@
+package hclbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test Diff() - whitespace, comments and ordering are ignored
+func TestDiff_Equivalent(t *testing.T) {
+	a, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "mesh-1"
+  description = "first line\n"
+  skip_creating_initial_policies = ["*"]
+  routing = {
+    zone_egress = true
+    tags        = { a = "1", b = var.b }
+  }
+}
+`)
+	require.NoError(t, err)
+	b, err := hclbuilder.FromString(`
+# The mesh
+resource "kong-mesh_mesh" "default" {
+  name                           = "mesh-1" # inline
+  skip_creating_initial_policies = [
+    "*",
+  ]
+  type                           = "Mesh"
+  description = <<EOT
+first line
+EOT
+  routing = {
+    tags = {
+      b = var.b
+      a = "1"
+    }
+    zone_egress = true
+  }
+}
+`)
+	require.NoError(t, err)
+
+	require.Empty(t, hclbuilder.Diff(a, b))
+	require.Empty(t, hclbuilder.Diff(b, a))
+}
+
+// Test Diff() - added, removed and changed blocks and attributes
+func TestDiff_Changes(t *testing.T) {
+	a, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh" "default" {
+  name = "mesh-1"
+  type = "Mesh"
+  routing = {
+    zone_egress = true
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  mesh = kong-mesh_mesh.default.name
+  spec = {
+    from = [
+      { default = { action = "Allow" } },
+      { default = { action = "Deny" } },
+    ]
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "old" {
+  mesh = "default"
+}
+`)
+	require.NoError(t, err)
+	b, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh" "default" {
+  name = "mesh-2"
+  routing = {
+    zone_egress = false
+    default_forbid_mesh_external_service_access = true
+  }
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  mesh = kong-mesh_mesh.other.name
+  spec = {
+    from = [
+      { default = { action = "Deny" } },
+    ]
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "new" {
+  mesh = "default"
+}
+`)
+	require.NoError(t, err)
+
+	changes := hclbuilder.Diff(a, b)
+	require.Equal(t, hclbuilder.Changes{
+		{Kind: hclbuilder.ChangeModified, Path: "resource.kong-mesh_mesh.default.name", Old: `"mesh-1"`, New: `"mesh-2"`},
+		{Kind: hclbuilder.ChangeAdded, Path: "resource.kong-mesh_mesh.default.routing.default_forbid_mesh_external_service_access", New: "true"},
+		{Kind: hclbuilder.ChangeModified, Path: "resource.kong-mesh_mesh.default.routing.zone_egress", Old: "true", New: "false"},
+		{Kind: hclbuilder.ChangeRemoved, Path: "resource.kong-mesh_mesh.default.type", Old: `"Mesh"`},
+		{Kind: hclbuilder.ChangeAdded, Path: "resource.kong-mesh_mesh.default.lifecycle", Block: true},
+		{Kind: hclbuilder.ChangeModified, Path: "resource.kong-mesh_mesh_traffic_permission.allow_all.mesh", Old: "kong-mesh_mesh.default.name", New: "kong-mesh_mesh.other.name"},
+		{Kind: hclbuilder.ChangeModified, Path: "resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[0].default.action", Old: `"Allow"`, New: `"Deny"`},
+		{Kind: hclbuilder.ChangeRemoved, Path: "resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[1]", Old: `{ default = { action = "Deny" } }`},
+		{Kind: hclbuilder.ChangeRemoved, Path: "resource.kong-mesh_mesh_traffic_permission.old", Block: true},
+		{Kind: hclbuilder.ChangeAdded, Path: "resource.kong-mesh_mesh_traffic_permission.new", Block: true},
+	}, changes)
+
+	require.Equal(t, `~ resource.kong-mesh_mesh.default.name = "mesh-1" -> "mesh-2"
++ resource.kong-mesh_mesh.default.routing.default_forbid_mesh_external_service_access = true
+~ resource.kong-mesh_mesh.default.routing.zone_egress = true -> false
+- resource.kong-mesh_mesh.default.type = "Mesh"
++ resource.kong-mesh_mesh.default.lifecycle {}
+~ resource.kong-mesh_mesh_traffic_permission.allow_all.mesh = kong-mesh_mesh.default.name -> kong-mesh_mesh.other.name
+~ resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[0].default.action = "Allow" -> "Deny"
+- resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[1] = { default = { action = "Deny" } }
+- resource.kong-mesh_mesh_traffic_permission.old {}
++ resource.kong-mesh_mesh_traffic_permission.new {}`, changes.String())
+}
+
+// Test Diff() - expressions are compared regardless of layout
+func TestDiff_Expressions(t *testing.T) {
+	a, err := hclbuilder.FromString(`
+resource "konnect_mesh" "m" {
+  name   = "${var.prefix}-mesh"
+  config = jsonencode({
+    a = 1
+    b = [var.x, var.y]
+  })
+  enabled = var.enabled ? true : false
+}
+`)
+	require.NoError(t, err)
+	b, err := hclbuilder.FromString(`
+resource "konnect_mesh" "m" {
+  name    = "${ var.prefix }-mesh"
+  config  = jsonencode({ a = 1, b = [var.x, var.y] })
+  enabled = var.enabled ? true : null
+}
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, hclbuilder.Changes{
+		{Kind: hclbuilder.ChangeModified, Path: "resource.konnect_mesh.m.enabled", Old: "var.enabled ? true : false", New: "var.enabled ? true : null"},
+	}, hclbuilder.Diff(a, b))
+}
+
+// Test Diff() - repeated blocks and empty builders
+func TestDiff_RepeatedBlocks(t *testing.T) {
+	a, err := hclbuilder.FromString(`
+resource "konnect_mesh" "m" {
+  provisioner "local-exec" {
+    command = "echo created"
+  }
+}
+`)
+	require.NoError(t, err)
+	b, err := hclbuilder.FromString(`
+resource "konnect_mesh" "m" {
+  provisioner "local-exec" {
+    command = "echo created"
+  }
+  provisioner "local-exec" {
+    command = "echo destroyed"
+    when    = destroy
+  }
+}
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, hclbuilder.Changes{
+		{Kind: hclbuilder.ChangeAdded, Path: `resource.konnect_mesh.m.provisioner.local-exec[1]`, Block: true},
+	}, hclbuilder.Diff(a, b))
+
+	require.Equal(t, hclbuilder.Changes{
+		{Kind: hclbuilder.ChangeRemoved, Path: "resource.konnect_mesh.m", Block: true},
+	}, hclbuilder.Diff(a, hclbuilder.New()))
+	require.Empty(t, hclbuilder.Diff(nil, hclbuilder.New()))
+}
@@ -0,0 +1,75 @@
+package hclbuilder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by PathError to describe why an operation could not be applied.
+var (
+	// ErrInvalidPath is returned when a path does not have the expected format.
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrBlockNotFound is returned when the targeted block does not exist.
+	ErrBlockNotFound = errors.New("block not found")
+	// ErrAttributeNotFound is returned when the targeted attribute does not exist.
+	ErrAttributeNotFound = errors.New("attribute not found")
+	// ErrNotResource is returned when a resource block is required but the block is of another kind.
+	ErrNotResource = errors.New("not a resource")
+	// ErrInvalidExpression is returned when an Expression (see Ref and Raw) is not valid HCL.
+	ErrInvalidExpression = errors.New("invalid expression")
+	// ErrUnsupportedValue is returned when a Go value cannot be converted to an HCL value.
+	ErrUnsupportedValue = errors.New("unsupported value")
+)
+
+// PathError records an operation that could not be applied to a path.
+//
+// Mutating methods of Builder and BlockBuilder do not fail immediately: they
+// record a PathError on the Builder and keep going, so that calls can still be
+// chained. Use Builder.Err or Builder.BuildE to check for failures.
+type PathError struct {
+	// Op is the name of the method that failed (e.g., "SetAttribute").
+	Op string
+	// Path is the path the method was called with.
+	Path string
+	// Err describes why the operation failed.
+	Err error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s %q: %s", e.Op, e.Path, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Err returns the errors recorded by operations on this builder, and on any
+// builder upserted into it, that could not be applied. Returns nil if all
+// operations succeeded.
+func (b *Builder) Err() error {
+	return errors.Join(b.collectErrors(make(map[*Builder]bool))...)
+}
+
+// BuildE returns the HCL configuration as a string together with Err, so that
+// callers can make sure the configuration was generated as requested.
+func (b *Builder) BuildE() (string, error) {
+	return b.Build(), b.Err()
+}
+
+// addError records a failed operation on the builder.
+func (b *Builder) addError(op, path string, err error) {
+	b.errs = append(b.errs, &PathError{Op: op, Path: path, Err: err})
+}
+
+func (b *Builder) collectErrors(seen map[*Builder]bool) []error {
+	if seen[b] {
+		return nil
+	}
+	seen[b] = true
+
+	errs := append([]error(nil), b.errs...)
+	for _, other := range b.upsertedBuilders.list() {
+		errs = append(errs, other.collectErrors(seen)...)
+	}
+	return errs
+}
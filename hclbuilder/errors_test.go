@@ -0,0 +1,151 @@
+package hclbuilder_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// requirePathError asserts that err contains a PathError for the given operation and path
+func requirePathError(t *testing.T, err error, op, path string, target error) {
+	t.Helper()
+	require.Error(t, err)
+	require.ErrorIs(t, err, target)
+
+	var pathErr *hclbuilder.PathError
+	require.ErrorAs(t, err, &pathErr)
+	require.Equal(t, op, pathErr.Op)
+	require.Equal(t, path, pathErr.Path)
+}
+
+// Test BuildE() - no errors
+func TestBuildE_NoErrors(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{
+		"name": "mesh-1",
+	})
+	builder.AddAttribute("type", "Mesh")
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	require.Equal(t, builder.Build(), result)
+	require.NoError(t, builder.Err())
+}
+
+// Test SetAttribute() - invalid path
+func TestErr_SetAttributeInvalidPath(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetAttribute("variable.name", "value")
+
+	requirePathError(t, builder.Err(), "SetAttribute", "variable.name", hclbuilder.ErrInvalidPath)
+}
+
+// Test SetBlock() - invalid path
+func TestErr_SetBlockInvalidPath(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource", map[string]any{"name": "mesh-1"})
+
+	_, err := builder.BuildE()
+	requirePathError(t, err, "SetBlock", "resource", hclbuilder.ErrInvalidPath)
+}
+
+// Test AddAttribute() - empty builder
+func TestErr_AddAttributeEmptyBuilder(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.AddAttribute("name", "mesh-1")
+
+	requirePathError(t, builder.Err(), "AddAttribute", "name", hclbuilder.ErrBlockNotFound)
+	require.Contains(t, builder.Err().Error(), "builder has no blocks")
+}
+
+// Test RemoveAttribute() - missing attributes
+func TestErr_RemoveAttributeMissing(t *testing.T) {
+	inputFile := filepath.Join("testdata", "remove-attribute-nested.input.tf")
+
+	tests := map[string]string{
+		"simple":        "labels",
+		"missing root":  "constraints.dataplane_proxy",
+		"missing field": "routing.missing",
+	}
+	for name, path := range tests {
+		t.Run(name, func(t *testing.T) {
+			mesh, err := hclbuilder.FromFile(inputFile)
+			require.NoError(t, err)
+
+			mesh.RemoveAttribute(path)
+
+			requirePathError(t, mesh.Err(), "RemoveAttribute", "resource.kong-mesh_mesh.default."+path, hclbuilder.ErrAttributeNotFound)
+		})
+	}
+}
+
+// Test RemoveBlock() - missing block
+func TestErr_RemoveBlockMissing(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.RemoveBlock("resource.kong-mesh_mesh.default")
+
+	requirePathError(t, builder.Err(), "RemoveBlock", "resource.kong-mesh_mesh.default", hclbuilder.ErrBlockNotFound)
+}
+
+// Test Remove() - blocks that are not in the builder
+func TestErr_RemoveMissing(t *testing.T) {
+	builder := hclbuilder.New()
+	variable, err := hclbuilder.FromFile(filepath.Join("testdata", "resource-path-non-resource.input.tf"))
+	require.NoError(t, err)
+
+	builder.Remove(variable)
+
+	requirePathError(t, builder.Err(), "Remove", "variable.test_var", hclbuilder.ErrBlockNotFound)
+}
+
+// Test Block() - mutating a missing block
+func TestErr_BlockMissing(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.Block("resource.kong-mesh_mesh.default").AddAttribute("name", "mesh-1")
+
+	requirePathError(t, builder.Err(), "AddAttribute", "resource.kong-mesh_mesh.default.name", hclbuilder.ErrBlockNotFound)
+}
+
+// Test Err() - errors of upserted builders are reported
+func TestErr_UpsertedBuilder(t *testing.T) {
+	builder := hclbuilder.New()
+	mesh, err := hclbuilder.FromFile(filepath.Join("testdata", "add-embed-and-mutate-mesh.input.tf"))
+	require.NoError(t, err)
+
+	builder.Upsert(mesh)
+	mesh.RemoveAttribute("routing")
+
+	_, err = builder.BuildE()
+	requirePathError(t, err, "RemoveAttribute", "resource.kong-mesh_mesh.default.routing", hclbuilder.ErrAttributeNotFound)
+	require.True(t, errors.Is(mesh.Err(), hclbuilder.ErrAttributeNotFound))
+}
+
+// Test Err() - errors of upserted builders are reported in upsert order
+func TestErr_UpsertedBuildersOrder(t *testing.T) {
+	builder := hclbuilder.New()
+	module := hclbuilder.NewModule()
+	var others []*hclbuilder.Builder
+	for i := 0; i < 10; i++ {
+		other := hclbuilder.New()
+		other.SetBlock(fmt.Sprintf("variable.v%d", i), map[string]any{"default": i})
+		builder.Upsert(other)
+		module.Upsert(other)
+		others = append(others, other)
+	}
+	for i, other := range others {
+		other.RemoveBlock(fmt.Sprintf("variable.missing%d", i))
+	}
+
+	var expected []string
+	for i := range others {
+		expected = append(expected, fmt.Sprintf(`RemoveBlock "variable.missing%d": block not found`, i))
+	}
+	require.Equal(t, strings.Join(expected, "\n"), builder.Err().Error())
+	require.Equal(t, strings.Join(expected, "\n"), module.Err().Error())
+}
@@ -0,0 +1,203 @@
+package hclbuilder
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Expression is an attribute value written as an HCL expression rather than
+// converted from a Go value. Expressions are accepted by SetAttribute,
+// SetBlock and AddAttribute, at the top level or nested in maps and slices.
+//
+// Use Ref, Raw, Literal or Func to create one.
+type Expression interface {
+	// tokens returns the tokens of the expression, or an error if the
+	// expression is invalid.
+	tokens() (hclwrite.Tokens, error)
+}
+
+type expressionFunc func() (hclwrite.Tokens, error)
+
+func (f expressionFunc) tokens() (hclwrite.Tokens, error) {
+	return f()
+}
+
+// Ref returns a reference to another object, such as a resource attribute,
+// a variable or a local value.
+// Example: hclbuilder.Ref("kong-mesh_mesh.default.name") writes mesh = kong-mesh_mesh.default.name.
+//
+// The operation using an invalid reference records ErrInvalidExpression.
+func Ref(traversal string) Expression {
+	return expressionFunc(func() (hclwrite.Tokens, error) {
+		if _, diags := hclsyntax.ParseTraversalAbs([]byte(traversal), "<ref>", hcl.InitialPos); diags.HasErrors() {
+			return nil, fmt.Errorf("%w: %q is not a reference: %s", ErrInvalidExpression, traversal, diags.Error())
+		}
+		return exprTokens([]byte(traversal))
+	})
+}
+
+// Raw is an arbitrary HCL expression, written as-is. GetAttribute returns
+// expressions that cannot be evaluated without context, such as references,
+// as Raw values.
+// Example: hclbuilder.Raw(`var.enabled ? "Allow" : "Deny"`).
+//
+// The operation using an expression that does not parse records ErrInvalidExpression.
+type Raw string
+
+func (r Raw) tokens() (hclwrite.Tokens, error) {
+	tokens, err := exprTokens([]byte(r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExpression, err)
+	}
+	return tokens, nil
+}
+
+// Literal returns a string that is always written as a quoted string, even if
+// it looks like an HCL expression (e.g. "true", "[]" or "var.name").
+// Template sequences are escaped.
+func Literal(value string) Expression {
+	return expressionFunc(func() (hclwrite.Tokens, error) {
+		return hclwrite.TokensForValue(cty.StringVal(value)), nil
+	})
+}
+
+// Func returns a function call. Arguments can be Go values or Expressions.
+// Example: hclbuilder.Func("jsonencode", map[string]any{"name": hclbuilder.Ref("var.name")}).
+func Func(name string, args ...any) Expression {
+	return expressionFunc(func() (hclwrite.Tokens, error) {
+		if !hclsyntax.ValidIdentifier(name) {
+			return nil, fmt.Errorf("%w: %q is not a valid function name", ErrInvalidExpression, name)
+		}
+		argTokens := make([]hclwrite.Tokens, len(args))
+		for i, arg := range args {
+			tokens, err := valueTokens(arg)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d of %s: %w", i, name, err)
+			}
+			argTokens[i] = tokens
+		}
+		return hclwrite.TokensForFunctionCall(name, argTokens...), nil
+	})
+}
+
+// Ref returns a reference to an attribute of the first block in this builder,
+// which must be a resource, data source or module (see Address).
+// Example: mesh.Ref("name") returns kong-mesh_mesh.default.name.
+func (b *Builder) Ref(attribute string) Expression {
+	return b.firstBlock().Ref(attribute)
+}
+
+// Ref returns a reference to an attribute of this block, which must be a
+// resource, data source or module (see Address). An empty attribute refers to
+// the block itself.
+func (bb *BlockBuilder) Ref(attribute string) Expression {
+	address := bb.Address()
+	if address == "" {
+		return expressionFunc(func() (hclwrite.Tokens, error) {
+			return nil, fmt.Errorf("%w: cannot reference %q", ErrNotResource, bb.path)
+		})
+	}
+	if attribute == "" {
+		return Ref(address)
+	}
+	return Ref(address + "." + attribute)
+}
+
+var expressionType = reflect.TypeOf((*Expression)(nil)).Elem()
+
+// valueTokens returns the tokens for a Go value, which may be or contain
+// Expressions.
+func valueTokens(value any) (hclwrite.Tokens, error) {
+	if expr, ok := value.(Expression); ok {
+		return expr.tokens()
+	}
+	if value == nil || !containsExpression(reflect.ValueOf(value)) {
+		val, err := convertToCtyValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return hclwrite.TokensForValue(val), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elems := make([]hclwrite.Tokens, rv.Len())
+		for i := range elems {
+			tokens, err := valueTokens(rv.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			elems[i] = tokens
+		}
+		return hclwrite.TokensForTuple(elems), nil
+	default:
+		// containsExpression only looks into maps with string keys
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+
+		items := make([]hclwrite.ObjectAttrTokens, len(keys))
+		for i, key := range keys {
+			tokens, err := valueTokens(rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			items[i] = hclwrite.ObjectAttrTokens{Name: objectKeyTokens(key), Value: tokens}
+		}
+		return hclwrite.TokensForObject(items), nil
+	}
+}
+
+// containsExpression reports whether rv is or contains an Expression, in
+// slices, arrays and maps with string keys.
+func containsExpression(rv reflect.Value) bool {
+	if !rv.IsValid() {
+		return false
+	}
+	if rv.Type().Implements(expressionType) && rv.Kind() != reflect.Interface {
+		return true
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !rv.IsNil() && containsExpression(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if containsExpression(rv.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return false
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			if containsExpression(iter.Value()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// objectKeyTokens returns the tokens of an object key, quoting it if it is not an identifier.
+func objectKeyTokens(key string) hclwrite.Tokens {
+	if hclsyntax.ValidIdentifier(key) {
+		return hclwrite.TokensForIdentifier(key)
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key))
+}
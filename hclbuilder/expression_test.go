@@ -0,0 +1,75 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test SetBlock(), SetAttribute() and AddAttribute() - expressions as values
+func TestExpressions(t *testing.T) {
+	mesh := hclbuilder.New()
+	mesh.SetBlock("resource.kong-mesh_mesh.default", map[string]any{
+		"name": "mesh-1",
+		"type": "Mesh",
+	})
+
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh_traffic_permission.allow_all", map[string]any{
+		"mesh":   mesh.Ref("name"),
+		"name":   hclbuilder.Raw(`"${var.prefix}-allow-all"`),
+		"type":   hclbuilder.Literal("MeshTrafficPermission"),
+		"labels": hclbuilder.Func("merge", hclbuilder.Ref("local.labels"), map[string]any{"team": "mesh"}),
+		"spec": map[string]any{
+			"from": []any{
+				map[string]any{
+					"target_ref": map[string]any{"kind": "Mesh"},
+					"default": map[string]any{
+						"action": hclbuilder.Raw(`var.allow ? "Allow" : "Deny"`),
+					},
+				},
+			},
+		},
+	})
+	builder.SetAttribute("variable.allow.default", hclbuilder.Literal("true"))
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").
+		AddAttribute("description", hclbuilder.Literal("[]")).
+		AddAttribute("spec.target_ref", map[string]any{
+			"kind": "Mesh",
+			"name": mesh.Ref("name"),
+		}).
+		AddAttribute("spec.rules", hclbuilder.Func("jsondecode", hclbuilder.Ref("var.rules")))
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "expressions.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test Ref(), Raw() and Func() - invalid expressions are recorded
+func TestExpressions_Invalid(t *testing.T) {
+	variable := hclbuilder.New()
+	variable.SetBlock("variable.mesh_name", map[string]any{"default": "mesh-1"})
+
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{
+		"name": hclbuilder.Ref(`var.name == "x"`),
+	})
+	builder.SetAttribute("resource.kong-mesh_mesh.other.name", hclbuilder.Raw("var."))
+	builder.SetBlock("resource.kong-mesh_mesh.func", map[string]any{
+		"name": hclbuilder.Func("not a function"),
+	})
+	builder.SetBlock("resource.kong-mesh_mesh.variable", map[string]any{
+		"name": variable.Ref("default"),
+	})
+
+	require.Empty(t, builder.Build())
+	err := builder.Err()
+	requirePathError(t, err, "SetBlock", "resource.kong-mesh_mesh.default", hclbuilder.ErrInvalidExpression)
+	require.ErrorContains(t, err, `SetAttribute "resource.kong-mesh_mesh.other.name"`)
+	require.ErrorContains(t, err, `"not a function" is not a valid function name`)
+	require.ErrorIs(t, err, hclbuilder.ErrNotResource)
+}
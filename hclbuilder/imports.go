@@ -0,0 +1,170 @@
+package hclbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ImportID returns the import ID of a Kong Mesh or Konnect object: a JSON
+// object with the cp_id, mesh and name fields that are not empty, as expected
+// by the providers.
+// Example: ImportID("", "default", "allow-all") returns {"mesh":"default","name":"allow-all"}.
+func ImportID(cpID, mesh, name string) string {
+	fields := make(map[string]string, 3)
+	for key, value := range map[string]string{"cp_id": cpID, "mesh": mesh, "name": name} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	// Marshaling a map of strings cannot fail, and sorts the keys
+	id, _ := json.Marshal(fields)
+	return string(id)
+}
+
+// AddImport adds an import block bringing an existing object under the
+// management of the other builder's resource, or of the other resource block.
+// id is a string (see ImportID) or an Expression. An import block with the
+// same target is replaced in place.
+// Example: builder.AddImport(mesh, hclbuilder.ImportID("", "", "default")) writes
+//
+//	import {
+//	  to = kong-mesh_mesh.default
+//	  id = "{\"name\":\"default\"}"
+//	}
+func (b *Builder) AddImport(to Addressable, id any) *Builder {
+	b = b.mutable()
+	address := to.Address()
+	if address == "" {
+		b.addError("AddImport", "import", fmt.Errorf("%w: import target is not a resource block", ErrNotResource))
+		return b
+	}
+	b.addImport("AddImport", address, id)
+	return b
+}
+
+// AddImportAddress adds an import block for the resource at the given
+// address, which can be an instance such as `konnect_mesh.m["a"]` or a
+// resource in a child module such as `module.zone.konnect_mesh.m` (see
+// AddImport). Data sources and modules cannot be imported.
+func (b *Builder) AddImportAddress(to string, id any) *Builder {
+	b = b.mutable()
+	b.addImport("AddImportAddress", to, id)
+	return b
+}
+
+// addImport adds or replaces the import block for the given address.
+func (b *Builder) addImport(op, to string, id any) {
+	toTokens, err := addressTokens(to)
+	if err != nil {
+		b.addError(op, "import", err)
+		return
+	}
+	if !isResourceAddress(string(toTokens.Bytes())) {
+		b.addError(op, "import", fmt.Errorf("%w: import target %s is not a resource", ErrNotResource, toTokens.Bytes()))
+		return
+	}
+	idTokens, err := valueTokens(id)
+	if err != nil {
+		b.addError(op, "import", err)
+		return
+	}
+
+	block := hclwrite.NewBlock("import", nil)
+	block.Body().SetAttributeRaw("to", toTokens)
+	block.Body().SetAttributeRaw("id", idTokens)
+	upsertBlock(b.file.Body(), block, MergeReplace, nil)
+}
+
+// AddMoved adds a moved block recording that the object at address from is
+// now at address to, so that renaming a resource does not destroy it. A moved
+// block with the same source is replaced in place.
+// Example: builder.AddMoved("kong-mesh_mesh.old", mesh.ResourcePath())
+func (b *Builder) AddMoved(from, to string) *Builder {
+	b = b.mutable()
+	fromTokens, err := addressTokens(from)
+	if err != nil {
+		b.addError("AddMoved", "moved", err)
+		return b
+	}
+	toTokens, err := addressTokens(to)
+	if err != nil {
+		b.addError("AddMoved", "moved", err)
+		return b
+	}
+
+	block := hclwrite.NewBlock("moved", nil)
+	block.Body().SetAttributeRaw("from", fromTokens)
+	block.Body().SetAttributeRaw("to", toTokens)
+	upsertBlock(b.file.Body(), block, MergeReplace, nil)
+	return b
+}
+
+// AddRemoved adds a removed block for the object at address from, which is
+// no longer in the configuration. If destroy is false, the object is
+// forgotten by Terraform instead of being destroyed. A removed block with the
+// same source is replaced in place.
+// Example: builder.AddRemoved("kong-mesh_mesh.old", false) writes
+//
+//	removed {
+//	  from = kong-mesh_mesh.old
+//	  lifecycle {
+//	    destroy = false
+//	  }
+//	}
+func (b *Builder) AddRemoved(from string, destroy bool) *Builder {
+	b = b.mutable()
+	fromTokens, err := addressTokens(from)
+	if err != nil {
+		b.addError("AddRemoved", "removed", err)
+		return b
+	}
+
+	block := hclwrite.NewBlock("removed", nil)
+	block.Body().SetAttributeRaw("from", fromTokens)
+	lifecycle := block.Body().AppendNewBlock("lifecycle", nil)
+	lifecycle.Body().SetAttributeValue("destroy", cty.BoolVal(destroy))
+	upsertBlock(b.file.Body(), block, MergeReplace, nil)
+	return b
+}
+
+// isResourceAddress reports whether an address refers to a managed resource,
+// possibly in a child module, rather than to a data source or to a module
+// itself.
+func isResourceAddress(address string) bool {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "<address>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return false
+	}
+
+	// Names of the traversal, skipping the instance keys
+	var names []string
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, step.Name)
+		case hcl.TraverseAttr:
+			names = append(names, step.Name)
+		}
+	}
+
+	// Skip the module path: module.name, possibly nested
+	for len(names) >= 2 && names[0] == "module" {
+		names = names[2:]
+	}
+	return len(names) >= 2 && names[0] != "data"
+}
+
+// addressTokens returns the tokens of an address, in canonical form (see
+// normalizeAddress).
+func addressTokens(address string) (hclwrite.Tokens, error) {
+	normalized, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return exprTokens([]byte(normalized))
+}
@@ -0,0 +1,84 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test ImportID()
+func TestImportID(t *testing.T) {
+	require.Equal(t, `{"name":"default"}`, hclbuilder.ImportID("", "", "default"))
+	require.Equal(t, `{"mesh":"default","name":"allow-all"}`, hclbuilder.ImportID("", "default", "allow-all"))
+	require.Equal(t, `{"cp_id":"cp-1","mesh":"default","name":"allow-all"}`, hclbuilder.ImportID("cp-1", "default", "allow-all"))
+}
+
+// Test AddImport(), AddMoved() and AddRemoved()
+func TestImports(t *testing.T) {
+	builder := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "http://localhost:5681")
+	mesh, err := hclbuilder.FromFile(filepath.Join("testdata", "add-embed-and-mutate-mesh.input.tf"))
+	require.NoError(t, err)
+	builder.Upsert(mesh)
+
+	builder.AddImport(mesh, hclbuilder.ImportID("", "", "default")).
+		AddImportAddress(`konnect_mesh.m["a"]`, hclbuilder.Func("jsonencode", map[string]any{
+			"cp_id": hclbuilder.Ref("var.cp_id"),
+			"name":  "a",
+		})).
+		AddMoved("kong-mesh_mesh.old", mesh.ResourcePath()).
+		AddRemoved("kong-mesh_mesh_traffic_permission.allow_all", false)
+
+	// Blocks about the same address are replaced in place
+	builder.AddImport(mesh, hclbuilder.ImportID("", "", "renamed")).
+		AddRemoved("kong-mesh_mesh_traffic_permission.allow_all", true)
+	require.Len(t, builder.Blocks("import"), 2)
+	require.Len(t, builder.Blocks("removed"), 1)
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "imports.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test Upsert() - import blocks are identified by their target
+func TestImports_Upsert(t *testing.T) {
+	imports := hclbuilder.New().
+		AddImportAddress("kong-mesh_mesh.a", hclbuilder.ImportID("", "", "a")).
+		AddImportAddress("kong-mesh_mesh.b", hclbuilder.ImportID("", "", "b"))
+
+	builder := hclbuilder.New().AddImportAddress("kong-mesh_mesh.a", hclbuilder.ImportID("", "", "old"))
+	builder.Upsert(imports)
+	require.Len(t, builder.Blocks("import"), 2)
+	require.NotContains(t, builder.Build(), "old")
+}
+
+// Test AddImport() - errors
+func TestImports_Errors(t *testing.T) {
+	builder := hclbuilder.New()
+	data := builder.AddMeshData("default", "default")
+	builder.AddImport(data, "default")
+	requirePathError(t, builder.Err(), "AddImport", "import", hclbuilder.ErrNotResource)
+
+	module, err := hclbuilder.FromString(`module "networking" {}`)
+	require.NoError(t, err)
+	builder = hclbuilder.New().AddImport(module, "default")
+	requirePathError(t, builder.Err(), "AddImport", "import", hclbuilder.ErrNotResource)
+
+	for _, to := range []string{"module.networking", `module.zones["eu"]`, "module.zones.module.mesh", "data.konnect_mesh.m", "module.networking.data.konnect_mesh.m"} {
+		builder = hclbuilder.New().AddImportAddress(to, "default")
+		requirePathError(t, builder.Err(), "AddImportAddress", "import", hclbuilder.ErrNotResource)
+		require.Empty(t, builder.Build())
+	}
+
+	// Resources in child modules can be imported
+	builder = hclbuilder.New().AddImportAddress(`module.zones["eu"].konnect_mesh.m`, "default")
+	require.NoError(t, builder.Err())
+	require.Contains(t, builder.Build(), `to = module.zones["eu"].konnect_mesh.m`)
+
+	builder = hclbuilder.New().AddMoved("kong-mesh_mesh.old", "not an address")
+	requirePathError(t, builder.Err(), "AddMoved", "moved", hclbuilder.ErrInvalidExpression)
+	require.Empty(t, builder.Build())
+}
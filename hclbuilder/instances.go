@@ -0,0 +1,241 @@
+package hclbuilder
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CountIndex returns a reference to the index of the current instance of a
+// block with count (see SetCount): count.index.
+func CountIndex() Expression {
+	return Ref("count.index")
+}
+
+// EachKey returns a reference to the key of the current instance of a block
+// with for_each (see SetForEach): each.key.
+func EachKey() Expression {
+	return Ref("each.key")
+}
+
+// EachValue returns a reference to the value of the current instance of a
+// block with for_each (see SetForEach): each.value.
+func EachValue() Expression {
+	return Ref("each.value")
+}
+
+// SetCount sets the count meta-argument of the first block in this builder
+// (see BlockBuilder.SetCount).
+func (b *Builder) SetCount(count any) *Builder {
+	return b.firstBlock().SetCount(count).builder
+}
+
+// SetForEach sets the for_each meta-argument of the first block in this
+// builder (see BlockBuilder.SetForEach).
+func (b *Builder) SetForEach(value any) *Builder {
+	return b.firstBlock().SetForEach(value).builder
+}
+
+// InstancePath returns the resource path of an instance of the first block
+// in this builder (see BlockBuilder.InstancePath).
+func (b *Builder) InstancePath(key any) string {
+	return b.firstBlock().InstancePath(key)
+}
+
+// InstanceRef returns a reference to an attribute of an instance of the first
+// block in this builder (see BlockBuilder.InstanceRef).
+func (b *Builder) InstanceRef(key any, attribute string) Expression {
+	return b.firstBlock().InstanceRef(key, attribute)
+}
+
+// DependsOnInstance adds an instance of the other builder's block, or of the
+// other block, to the depends_on attribute of the first block in this builder
+// (see BlockBuilder.DependsOnInstance).
+func (b *Builder) DependsOnInstance(other Addressable, key any) *Builder {
+	return b.firstBlock().DependsOnInstance(other, key).builder
+}
+
+// SetCount sets the count meta-argument of this block, an integer or an
+// Expression, replacing for_each. Meta-arguments are written at the top of
+// the block.
+// Example: builder.Block("resource.kong-mesh_mesh.default").SetCount(hclbuilder.Ref("var.mesh_count"))
+func (bb *BlockBuilder) SetCount(count any) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("SetCount", "count")
+		return bb
+	}
+
+	tokens, err := valueTokens(count)
+	if err != nil {
+		bb.addError("SetCount", "count", err)
+		return bb
+	}
+	setMetaArgument(bb.block.Body(), "count", tokens, "for_each")
+	return bb
+}
+
+// SetForEach sets the for_each meta-argument of this block, replacing count.
+// Slices are written as sets, maps as objects and Expressions as-is.
+// Example: builder.Block("resource.kong-mesh_mesh.default").SetForEach([]string{"a", "b"})
+// writes for_each = toset(["a", "b"]); use EachKey() and EachValue() in the
+// attributes of the block.
+func (bb *BlockBuilder) SetForEach(value any) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("SetForEach", "for_each")
+		return bb
+	}
+
+	if _, ok := value.(Expression); !ok && value != nil {
+		if kind := reflect.TypeOf(value).Kind(); kind == reflect.Slice || kind == reflect.Array {
+			value = Func("toset", value)
+		}
+	}
+	tokens, err := valueTokens(value)
+	if err != nil {
+		bb.addError("SetForEach", "for_each", err)
+		return bb
+	}
+	setMetaArgument(bb.block.Body(), "for_each", tokens, "count")
+	return bb
+}
+
+// InstancePath returns the resource path of an instance of this block, for a
+// resource with count (an integer key) or for_each (a string key).
+// Example: `konnect_mesh.m["a"]`. Returns empty string if the block is not a
+// resource or the key is neither an integer nor a string.
+func (bb *BlockBuilder) InstancePath(key any) string {
+	path := bb.ResourcePath()
+	if path == "" {
+		return ""
+	}
+	index, err := instanceIndex(key)
+	if err != nil {
+		return ""
+	}
+	return path + index
+}
+
+// InstanceRef returns a reference to an attribute of an instance of this
+// block, which must be a resource, data source or module (see Address). The
+// key is an integer, a string or an Expression such as EachKey(). An empty
+// attribute refers to the instance itself.
+// Example: mesh.InstanceRef(hclbuilder.EachKey(), "name") returns kong-mesh_mesh.default[each.key].name.
+func (bb *BlockBuilder) InstanceRef(key any, attribute string) Expression {
+	address := bb.Address()
+	if address == "" {
+		return expressionFunc(func() (hclwrite.Tokens, error) {
+			return nil, fmt.Errorf("%w: cannot reference %q", ErrNotResource, bb.path)
+		})
+	}
+	index, err := instanceIndex(key)
+	if err != nil {
+		return expressionFunc(func() (hclwrite.Tokens, error) {
+			return nil, err
+		})
+	}
+	if attribute != "" {
+		index += "." + attribute
+	}
+	return Raw(address + index)
+}
+
+// DependsOnInstance adds an instance of the other builder's block, or of the
+// other block, to the depends_on attribute of this block. The key is an
+// integer or a string.
+// Example: policy.DependsOnInstance(mesh, "a") adds kong-mesh_mesh.default["a"].
+func (bb *BlockBuilder) DependsOnInstance(other Addressable, key any) *BlockBuilder {
+	bb = bb.mutable()
+	address := other.Address()
+	if address == "" {
+		bb.addError("DependsOnInstance", "depends_on", fmt.Errorf("%w: other is not a resource, data source or module block", ErrNotResource))
+		return bb
+	}
+	index, err := instanceIndex(key)
+	if err != nil {
+		bb.addError("DependsOnInstance", "depends_on", err)
+		return bb
+	}
+	return bb.editDependencies("DependsOnInstance", func(deps []string) ([]string, error) {
+		normalized, err := normalizeAddress(address + index)
+		if err != nil {
+			return nil, err
+		}
+		return addDependencies(deps, normalized), nil
+	})
+}
+
+// instanceIndex returns the index selecting an instance by key: [0] for an
+// integer, ["a"] for a string, or the expression in brackets.
+func instanceIndex(key any) (string, error) {
+	if expr, ok := key.(Expression); ok {
+		tokens, err := expr.tokens()
+		if err != nil {
+			return "", err
+		}
+		return "[" + string(tokens.Bytes()) + "]", nil
+	}
+
+	rv := reflect.ValueOf(key)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("[%d]", rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("[%d]", rv.Uint()), nil
+	case reflect.String:
+		return "[" + string(hclwrite.TokensForValue(cty.StringVal(rv.String())).Bytes()) + "]", nil
+	default:
+		return "", fmt.Errorf("%w: instance key of type %T", ErrUnsupportedValue, key)
+	}
+}
+
+// setMetaArgument sets a meta-argument such as count at the top of body,
+// followed by a blank line, as is usual in Terraform configurations. An
+// existing one, or one it excludes, is replaced in place.
+func setMetaArgument(body *hclwrite.Body, name string, tokens hclwrite.Tokens, excludes ...string) {
+	if body.GetAttribute(name) != nil {
+		body.SetAttributeRaw(name, tokens)
+		for _, exclude := range excludes {
+			body.RemoveAttribute(exclude)
+		}
+		return
+	}
+
+	for _, exclude := range excludes {
+		if body.GetAttribute(exclude) == nil {
+			continue
+		}
+		rebuildBody(body, func(item bodyItem) {
+			if item.attribute != nil && item.name == exclude {
+				body.SetAttributeRaw(name, tokens)
+				return
+			}
+			appendItem(body, item)
+		})
+		return
+	}
+
+	first := true
+	rebuildBody(body, func(item bodyItem) {
+		if first {
+			first = false
+			// The line break after the opening brace stays first
+			if item.block == nil && item.attribute == nil && item.tokens[0].Type == hclsyntax.TokenNewline {
+				body.AppendUnstructuredTokens(item.tokens[:1])
+				item.tokens = item.tokens[1:]
+			}
+			body.SetAttributeRaw(name, tokens)
+			body.AppendNewline()
+		}
+		if len(item.tokens) > 0 {
+			appendItem(body, item)
+		}
+	})
+	if first {
+		body.SetAttributeRaw(name, tokens)
+	}
+}
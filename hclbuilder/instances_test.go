@@ -0,0 +1,82 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test SetForEach() and SetCount() - meta-arguments and instance references
+func TestInstances(t *testing.T) {
+	builder := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "http://localhost:5681")
+	builder.ProviderProperty = hclbuilder.KongMesh
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{
+		"name": hclbuilder.Raw(`"mesh-${each.key}"`),
+		"type": "Mesh",
+	})
+	mesh := builder.Block("resource.kong-mesh_mesh.default").SetForEach([]string{"a", "b"})
+
+	builder.AddPolicy("mesh_traffic_permission", "allow-all", "allow_all", "", nil)
+	policy := builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").
+		SetCount(hclbuilder.Ref("var.policy_count")).
+		AddAttribute("name", hclbuilder.Func("format", "allow-all-%d", hclbuilder.CountIndex())).
+		AddAttribute("mesh", mesh.InstanceRef("a", "name")).
+		DependsOnInstance(mesh, "a")
+
+	require.Equal(t, `kong-mesh_mesh.default["a"]`, mesh.InstancePath("a"))
+	require.Equal(t, "kong-mesh_mesh_traffic_permission.allow_all[1]", policy.InstancePath(1))
+	require.Empty(t, builder.Block("provider.kong-mesh").InstancePath(0))
+
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").SetCount(2)
+	builder.SetBlock("resource.kong-mesh_mesh_traffic_permission.per_mesh", map[string]any{
+		"mesh": mesh.InstanceRef(hclbuilder.EachKey(), "name"),
+		"name": hclbuilder.EachValue(),
+	})
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.per_mesh").SetForEach(mesh.Ref(""))
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "instances.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test SetForEach() - for_each replaces count in place
+func TestSetForEach_ReplacesCount(t *testing.T) {
+	builder, err := hclbuilder.FromString(`resource "kong-mesh_mesh" "default" {
+  type  = "Mesh"
+  count = 2
+  name  = "mesh-${count.index}"
+}
+`)
+	require.NoError(t, err)
+
+	builder.SetForEach(map[string]any{"eu": "zone-eu"}).
+		AddAttribute("name", hclbuilder.Raw(`"mesh-${each.value}"`))
+	require.Equal(t, `resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  for_each = {
+    eu = "zone-eu"
+  }
+  name = "mesh-${each.value}"
+}
+`, builder.Build())
+}
+
+// Test SetCount() and DependsOnInstance() - errors
+func TestInstances_Errors(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	mesh := builder.Block("resource.kong-mesh_mesh.default")
+
+	builder.Block("resource.kong-mesh_mesh.missing").SetCount(1)
+	requirePathError(t, builder.Err(), "SetCount", "resource.kong-mesh_mesh.missing.count", hclbuilder.ErrBlockNotFound)
+
+	builder = hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.DependsOnInstance(mesh, 1.5)
+	requirePathError(t, builder.Err(), "DependsOnInstance", "resource.kong-mesh_mesh.default.depends_on", hclbuilder.ErrUnsupportedValue)
+	require.Empty(t, mesh.InstancePath(1.5))
+}
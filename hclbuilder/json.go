@@ -0,0 +1,738 @@
+package hclbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Terraform JSON configuration syntax (.tf.json) is mapped to the native
+// syntax as Terraform does: labels are nested object keys, strings are
+// templates where "${...}" holds an expression, and "//" properties are
+// comments. JSON has no schema telling nested blocks from object attributes,
+// so only the nested blocks Terraform itself defines are read as blocks;
+// other objects are attributes, as in the Kong providers.
+
+// jsonNestedBlocks are the label counts of the nested block types of each
+// block type.
+var jsonNestedBlocks = map[string]map[string]int{
+	"resource":    {"lifecycle": 0, "provisioner": 1, "connection": 0, "dynamic": 1, "timeouts": 0},
+	"data":        {"lifecycle": 0, "dynamic": 1, "timeouts": 0},
+	"ephemeral":   {"lifecycle": 0, "dynamic": 1},
+	"removed":     {"lifecycle": 0, "provisioner": 1, "connection": 0},
+	"provisioner": {"connection": 0},
+	"dynamic":     {"content": 0},
+	"lifecycle":   {"precondition": 0, "postcondition": 0},
+	"variable":    {"validation": 0},
+	"output":      {"precondition": 0},
+	"check":       {"data": 2, "assert": 0},
+	"terraform":   {"required_providers": 0, "backend": 1, "cloud": 0, "provider_meta": 1},
+}
+
+// jsonTraversalAttributes are the attributes of each block type (any block
+// type for "") that Terraform reads as references or keywords rather than
+// templates in JSON: they are written as plain strings, without "${...}".
+var jsonTraversalAttributes = map[string][]string{
+	"":          {"depends_on"},
+	"resource":  {"provider"},
+	"data":      {"provider"},
+	"ephemeral": {"provider"},
+	"module":    {"providers"},
+	"lifecycle": {"ignore_changes", "replace_triggered_by"},
+	"variable":  {"type"},
+	"import":    {"to"},
+	"moved":     {"from", "to"},
+	"removed":   {"from"},
+}
+
+// jsonLiteralAttributes are the attributes of each block type that Terraform
+// reads as literal values in JSON: their strings are not templates, so
+// "${...}" is kept as-is in the value.
+var jsonLiteralAttributes = map[string][]string{
+	"variable": {"default"},
+}
+
+// jsonStringMode is how the strings of an attribute are read in JSON.
+type jsonStringMode int
+
+const (
+	// jsonTemplate strings are templates where "${...}" holds an expression.
+	jsonTemplate jsonStringMode = iota
+	// jsonTraversal strings are expressions written as-is (see
+	// jsonTraversalAttributes).
+	jsonTraversal
+	// jsonLiteral strings are literal values (see jsonLiteralAttributes).
+	jsonLiteral
+)
+
+// jsonObject is a JSON object keeping the order of its properties.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value any
+}
+
+// FromJSON parses a configuration in Terraform JSON syntax (.tf.json). The
+// resulting builder holds the equivalent native syntax configuration:
+// "${...}" strings become expressions and "//" properties comments.
+// Example:
+//
+//	hclbuilder.FromJSON(`{"resource": {"kong-mesh_mesh": {"default": {"type": "Mesh", "name": "${var.mesh_name}"}}}}`)
+//
+// is equivalent to
+//
+//	resource "kong-mesh_mesh" "default" {
+//	  type = "Mesh"
+//	  name = var.mesh_name
+//	}
+func FromJSON(content string) (*Builder, error) {
+	root, err := decodeJSON([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	obj, ok := root.(jsonObject)
+	if !ok {
+		return nil, errors.New("parsing JSON: the configuration must be an object")
+	}
+
+	b := New()
+	body := b.file.Body()
+	for _, member := range obj {
+		var err error
+		labelCount, known := blockLabelCount(member.key)
+		switch {
+		case member.key == "//":
+			// Set apart from the first block, which would be described otherwise
+			body.AppendUnstructuredTokens(commentLines(jsonComment(member.value)))
+			body.AppendNewline()
+		case known:
+			err = appendJSONBlocks(body, member.key, labelCount, nil, member.value)
+		default:
+			err = setJSONAttribute(body, "", member.key, member.value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing JSON: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// BuildJSON returns the configuration in Terraform JSON syntax, together
+// with Err (see BuildE). Blocks are grouped by type in the order they first
+// appear. The comments directly above blocks are written as their "//"
+// property, and the other top-level comments as the "//" property of the
+// configuration; comments on attributes are dropped.
+// Example: `name = var.mesh_name` is written as "name": "${var.mesh_name}".
+func (b *Builder) BuildJSON() (string, error) {
+	out, err := b.jsonBytes()
+	if err != nil {
+		return "", err
+	}
+	return string(out), b.Err()
+}
+
+// jsonBytes returns the configuration in Terraform JSON syntax, indented.
+func (b *Builder) jsonBytes() ([]byte, error) {
+	root, err := bodyJSON(b.file.Body(), "")
+	if err != nil {
+		return nil, fmt.Errorf("converting to JSON: %w", err)
+	}
+
+	var compact bytes.Buffer
+	if err := writeJSON(&compact, root); err != nil {
+		return nil, fmt.Errorf("converting to JSON: %w", err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("converting to JSON: %w", err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// decodeJSON decodes a JSON document, keeping the order of object properties.
+func decodeJSON(content []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	value, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the configuration")
+	}
+	return value, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{key: key.(string), value: value})
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err = dec.Token()
+		return arr, err
+	default:
+		return token, nil
+	}
+}
+
+// appendJSONBlocks appends to body the blocks of the given type described by
+// value, whose first labels are already known. Each level of labels is an
+// object keyed by the label, and arrays can be used at any level to repeat
+// blocks.
+func appendJSONBlocks(body *hclwrite.Body, blockType string, labelCount int, labels []string, value any) error {
+	switch v := value.(type) {
+	case []any:
+		for _, elem := range v {
+			if err := appendJSONBlocks(body, blockType, labelCount, labels, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case jsonObject:
+		if len(labels) < labelCount {
+			for _, member := range v {
+				if member.key == "//" {
+					continue
+				}
+				if err := appendJSONBlocks(body, blockType, labelCount, append(labels[:len(labels):len(labels)], member.key), member.value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		block := hclwrite.NewBlock(blockType, labels)
+		var comment string
+		for _, member := range v {
+			var err error
+			nestedLabelCount, nested := jsonNestedBlocks[blockType][member.key]
+			switch {
+			case member.key == "//":
+				comment = jsonComment(member.value)
+			case nested:
+				err = appendJSONBlocks(block.Body(), member.key, nestedLabelCount, nil, member.value)
+			default:
+				err = setJSONAttribute(block.Body(), blockType, member.key, member.value)
+			}
+			if err != nil {
+				return fmt.Errorf("%s.%w", blockPath(block), err)
+			}
+		}
+
+		if comment != "" {
+			body.AppendUnstructuredTokens(commentLines(comment))
+		}
+		body.AppendBlock(block)
+		return nil
+	default:
+		return fmt.Errorf("%s: expected an object, got %s", JoinPath(append([]string{blockType}, labels...)...), jsonTypeName(value))
+	}
+}
+
+// setJSONAttribute sets the attribute name of a block of the given type from
+// its JSON value.
+func setJSONAttribute(body *hclwrite.Body, blockType, name string, value any) error {
+	src, err := jsonExprSource(value, jsonAttributeMode(blockType, name))
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	tokens, err := exprTokens([]byte(src))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", name, ErrInvalidExpression, err)
+	}
+	body.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// jsonAttributeMode returns how the strings of the attribute name of a block
+// of the given type are read in JSON.
+func jsonAttributeMode(blockType, name string) jsonStringMode {
+	switch {
+	case slices.Contains(jsonLiteralAttributes[blockType], name):
+		return jsonLiteral
+	case slices.Contains(jsonTraversalAttributes[""], name) || slices.Contains(jsonTraversalAttributes[blockType], name):
+		return jsonTraversal
+	default:
+		return jsonTemplate
+	}
+}
+
+// jsonExprSource returns the native syntax source of a JSON value, its
+// strings being read according to mode.
+func jsonExprSource(value any, mode jsonStringMode) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return jsonStringSource(v, mode)
+	case []any:
+		elems := make([]string, len(v))
+		for i, elem := range v {
+			src, err := jsonExprSource(elem, mode)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = src
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	case jsonObject:
+		if len(v) == 0 {
+			return "{}", nil
+		}
+		var src strings.Builder
+		src.WriteString("{\n")
+		for _, member := range v {
+			value, err := jsonExprSource(member.value, mode)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&src, "%s = %s\n", objectKeySource(member.key), value)
+		}
+		src.WriteString("}")
+		return src.String(), nil
+	default:
+		return "", fmt.Errorf("%w: JSON value of type %T", ErrUnsupportedValue, value)
+	}
+}
+
+// jsonStringSource returns the native syntax source of a JSON string: the
+// expression of a "${...}" string, or a quoted template. Literal strings are
+// quoted with their template sequences escaped, and traversal strings other
+// than "${...}" are expressions as-is, neither being parsed as templates.
+func jsonStringSource(s string, mode jsonStringMode) (string, error) {
+	switch {
+	case mode == jsonLiteral:
+		return string(hclwrite.TokensForValue(cty.StringVal(s)).Bytes()), nil
+	case mode == jsonTraversal && !(strings.HasPrefix(s, "${") && strings.HasSuffix(s, "}")):
+		return s, nil
+	}
+
+	tmpl, diags := hclsyntax.ParseTemplate([]byte(s), "<json>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("%w: %s", ErrInvalidExpression, diags.Error())
+	}
+
+	switch t := tmpl.(type) {
+	case *hclsyntax.TemplateWrapExpr:
+		rng := t.Wrapped.Range()
+		return s[rng.Start.Byte:rng.End.Byte], nil
+	case *hclsyntax.TemplateExpr:
+		if mode == jsonTraversal {
+			return s, nil
+		}
+		// Literal parts are escaped for a quoted string, the
+		// interpolations and directives between them are kept as-is
+		return `"` + rewriteTemplateLiterals([]byte(s), t, 0, len(s), quoteTemplateLiteral) + `"`, nil
+	default:
+		return s, nil
+	}
+}
+
+// rewriteTemplateLiterals returns the source of a template between the
+// offsets start and end of src, which the ranges of tmpl refer to, with the
+// source of its top-level literal parts replaced with rewrite applied to
+// their value.
+func rewriteTemplateLiterals(src []byte, tmpl *hclsyntax.TemplateExpr, start, end int, rewrite func(string) string) string {
+	var out strings.Builder
+	pos := start
+	for _, part := range tmpl.Parts {
+		literal, ok := part.(*hclsyntax.LiteralValueExpr)
+		if !ok || !literal.Val.Type().Equals(cty.String) {
+			continue
+		}
+		rng := literal.Range()
+		out.Write(src[pos:rng.Start.Byte])
+		out.WriteString(rewrite(literal.Val.AsString()))
+		pos = rng.End.Byte
+	}
+	if end > pos {
+		out.Write(src[pos:end])
+	}
+	return out.String()
+}
+
+// quoteTemplateLiteral escapes a literal part of a template for a native
+// syntax quoted string.
+func quoteTemplateLiteral(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s)
+	return escapeTemplateLiteral(s)
+}
+
+// escapeTemplateLiteral escapes the template sequences of a literal string.
+func escapeTemplateLiteral(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+}
+
+// jsonComment returns the text of a "//" property.
+func jsonComment(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		lines := make([]string, 0, len(v))
+		for _, line := range v {
+			if s, ok := line.(string); ok {
+				lines = append(lines, s)
+			}
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return ""
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// bodyJSON returns the JSON object of a body of a block of the given type,
+// "" for the file: its attributes and nested blocks in order, nested blocks
+// being grouped by type.
+func bodyJSON(body *hclwrite.Body, blockType string) (*jsonObject, error) {
+	obj := &jsonObject{}
+	var comments, standalone hclwrite.Tokens
+	for _, item := range bodyItems(body) {
+		var trailing hclwrite.Tokens
+		switch {
+		case item.block != nil:
+			blockObj, err := bodyJSON(item.block.Body(), item.block.Type())
+			if err != nil {
+				return nil, err
+			}
+			comments = append(comments, leadComments(item.tokens)...)
+			if comment := commentText(comments); comment != "" {
+				*blockObj = append(jsonObject{{key: "//", value: comment}}, *blockObj...)
+			}
+			// Labels are nested object keys, repeated blocks are gathered in an array
+			labels := item.block.Labels()
+			if len(labels) == 0 {
+				obj.add(item.block.Type(), blockObj, true)
+				break
+			}
+			parent := obj.child(item.block.Type())
+			for _, label := range labels[:len(labels)-1] {
+				parent = parent.child(label)
+			}
+			parent.add(labels[len(labels)-1], blockObj, true)
+		case item.attribute != nil:
+			value, err := attributeJSON(item.attribute, jsonAttributeMode(blockType, item.name))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", item.name, err)
+			}
+			obj.add(item.name, value, false)
+			standalone = append(standalone, comments...)
+		default:
+			trailing = trailingComments(item.tokens)
+			standalone = append(standalone, commentTokens(item.tokens[:len(item.tokens)-len(trailing)])...)
+		}
+		comments = trailing
+	}
+	standalone = append(standalone, comments...)
+
+	// Comments that do not describe a block are only kept at the top level
+	if comment := commentText(standalone); blockType == "" && comment != "" {
+		*obj = append(jsonObject{{key: "//", value: comment}}, *obj...)
+	}
+	return obj, nil
+}
+
+// child returns the object of the property key, adding it if missing.
+func (o *jsonObject) child(key string) *jsonObject {
+	for _, member := range *o {
+		if member.key == key {
+			if child, ok := member.value.(*jsonObject); ok {
+				return child
+			}
+		}
+	}
+	child := &jsonObject{}
+	*o = append(*o, jsonMember{key: key, value: child})
+	return child
+}
+
+// add adds a property. If repeat is set, the values of a property added
+// several times are gathered in an array.
+func (o *jsonObject) add(key string, value any, repeat bool) {
+	if repeat {
+		for i, member := range *o {
+			if member.key != key {
+				continue
+			}
+			if values, ok := member.value.([]any); ok {
+				(*o)[i].value = append(values, value)
+			} else {
+				(*o)[i].value = []any{member.value, value}
+			}
+			return
+		}
+	}
+	*o = append(*o, jsonMember{key: key, value: value})
+}
+
+// trailingComments returns the comments unstructured tokens end with.
+func trailingComments(tokens hclwrite.Tokens) hclwrite.Tokens {
+	i := len(tokens)
+	for i > 0 && tokens[i-1].Type == hclsyntax.TokenComment {
+		i--
+	}
+	return tokens[i:]
+}
+
+// commentText returns the text of comment tokens, without comment markers.
+func commentText(comments hclwrite.Tokens) string {
+	var lines []string
+	for _, token := range comments {
+		text := strings.TrimSpace(string(token.Bytes))
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "/*"):
+			text = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/"))
+		case strings.HasPrefix(text, "//"):
+			text = strings.TrimPrefix(strings.TrimPrefix(text, "//"), " ")
+		default:
+			text = strings.TrimPrefix(strings.TrimPrefix(text, "#"), " ")
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// attributeJSON returns the JSON value of an attribute, its strings being
+// written according to mode. For traversal attributes, the expression, or
+// each element of a tuple or object, is written as-is in a plain string (see
+// jsonTraversalAttributes).
+func attributeJSON(attr *hclwrite.Attribute, mode jsonStringMode) (any, error) {
+	src := bytes.TrimSpace(exprSource(attr))
+	expr, err := parseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case jsonTraversal:
+		return traversalJSON(src, expr), nil
+	case jsonLiteral:
+		return literalExprJSON(src, expr), nil
+	default:
+		return exprJSON(src, expr), nil
+	}
+}
+
+// literalExprJSON returns the JSON value of an expression read as a literal
+// value (see jsonLiteralAttributes): strings are written unescaped. Other
+// expressions are written as by exprJSON.
+func literalExprJSON(src []byte, expr hclsyntax.Expression) any {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		elems := make([]any, len(e.Exprs))
+		for i, elem := range e.Exprs {
+			elems[i] = literalExprJSON(src, elem)
+		}
+		return elems
+	case *hclsyntax.ObjectConsExpr:
+		obj := &jsonObject{}
+		for _, item := range e.Items {
+			key, ok := objectItemKey(item)
+			if !ok {
+				return exprJSON(src, expr)
+			}
+			obj.add(key, literalExprJSON(src, item.ValueExpr), false)
+		}
+		return obj
+	}
+	if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() && !val.IsNull() && val.Type().Equals(cty.String) {
+		return val.AsString()
+	}
+	return exprJSON(src, expr)
+}
+
+func traversalJSON(src []byte, expr hclsyntax.Expression) any {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		elems := make([]any, len(e.Exprs))
+		for i, elem := range e.Exprs {
+			elems[i] = string(rangeSource(src, elem.Range()))
+		}
+		return elems
+	case *hclsyntax.ObjectConsExpr:
+		obj := &jsonObject{}
+		for _, item := range e.Items {
+			key, ok := objectItemKey(item)
+			if !ok {
+				return string(src)
+			}
+			obj.add(key, string(rangeSource(src, item.ValueExpr.Range())), false)
+		}
+		return obj
+	default:
+		return string(rangeSource(src, expr.Range()))
+	}
+}
+
+// exprJSON returns the JSON value of an expression: literals as JSON values,
+// tuples and objects element by element, quoted strings as templates, and
+// "${...}" strings for other expressions.
+func exprJSON(src []byte, expr hclsyntax.Expression) any {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return literalJSON(e.Val, src, e)
+	case *hclsyntax.UnaryOpExpr:
+		// Negative numbers
+		if _, ok := e.Val.(*hclsyntax.LiteralValueExpr); ok {
+			if val, diags := e.Value(nil); !diags.HasErrors() {
+				return literalJSON(val, src, e)
+			}
+		}
+	case *hclsyntax.TupleConsExpr:
+		elems := make([]any, len(e.Exprs))
+		for i, elem := range e.Exprs {
+			elems[i] = exprJSON(src, elem)
+		}
+		return elems
+	case *hclsyntax.ObjectConsExpr:
+		obj := &jsonObject{}
+		for _, item := range e.Items {
+			key, ok := objectItemKey(item)
+			if !ok {
+				return interpolationJSON(src, expr)
+			}
+			obj.add(escapeTemplateLiteral(key), exprJSON(src, item.ValueExpr), false)
+		}
+		return obj
+	case *hclsyntax.TemplateExpr:
+		rng := e.Range()
+		if src[rng.Start.Byte] == '"' {
+			// The literal parts are unescaped, interpolations and directives
+			// are kept as-is
+			return rewriteTemplateLiterals(src, e, rng.Start.Byte+1, rng.End.Byte-1, escapeTemplateLiteral)
+		}
+		if e.IsStringLiteral() {
+			// Heredoc without interpolations
+			val, _ := e.Value(nil)
+			return escapeTemplateLiteral(val.AsString())
+		}
+	}
+	return interpolationJSON(src, expr)
+}
+
+// interpolationJSON returns the "${...}" string of an expression.
+func interpolationJSON(src []byte, expr hclsyntax.Expression) string {
+	exprSrc := string(rangeSource(src, expr.Range()))
+	if strings.HasPrefix(exprSrc, "<<") {
+		// A heredoc ends with a newline
+		exprSrc += "\n"
+	}
+	return "${" + exprSrc + "}"
+}
+
+func literalJSON(val cty.Value, src []byte, expr hclsyntax.Expression) any {
+	switch {
+	case val.IsNull():
+		return nil
+	case val.Type().Equals(cty.Bool):
+		return val.True()
+	case val.Type().Equals(cty.Number):
+		return json.Number(val.AsBigFloat().Text('f', -1))
+	case val.Type().Equals(cty.String):
+		return escapeTemplateLiteral(val.AsString())
+	default:
+		return interpolationJSON(src, expr)
+	}
+}
+
+// writeJSON writes a value built by bodyJSON as compact JSON. HTML characters
+// such as > in version constraints are not escaped.
+func writeJSON(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case *jsonObject:
+		buf.WriteByte('{')
+		for i, member := range *v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, member.key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeJSON(buf, member.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case []any:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		// Encode ends values with a newline
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+package hclbuilder_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test FromJSON() - Terraform JSON syntax to native syntax
+func TestFromJSON(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "terraform-json.input.tf.json"))
+	require.NoError(t, err)
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "terraform-json.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test BuildJSON() - native syntax to Terraform JSON syntax
+func TestBuildJSON(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "terraform-json.golden.tf"))
+	require.NoError(t, err)
+
+	result, err := builder.BuildJSON()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "terraform-json.golden.tf.json")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test BuildJSON() and FromJSON() - every golden file reads back the same from JSON
+func TestBuildJSON_RoundTrip(t *testing.T) {
+	goldenFiles, err := filepath.Glob(filepath.Join("testdata", "*.golden.tf"))
+	require.NoError(t, err)
+	require.NotEmpty(t, goldenFiles)
+
+	for _, goldenFile := range goldenFiles {
+		t.Run(filepath.Base(goldenFile), func(t *testing.T) {
+			builder, err := hclbuilder.FromFile(goldenFile)
+			require.NoError(t, err)
+
+			content, err := builder.BuildJSON()
+			require.NoError(t, err)
+			reloaded, err := hclbuilder.FromJSON(content)
+			require.NoError(t, err)
+			require.Empty(t, hclbuilder.Diff(builder, reloaded).String())
+
+			again, err := reloaded.BuildJSON()
+			require.NoError(t, err)
+			require.Equal(t, content, again)
+		})
+	}
+}
+
+// Test FromJSON() and BuildJSON() - variable defaults are literal values and types are expressions
+func TestFromJSON_VariableDefault(t *testing.T) {
+	content := `{"variable":{"greeting":{"type":"map(string)","default":{"text":"Hello ${name}","directive":"%{if x}"}}}}`
+	builder, err := hclbuilder.FromJSON(content)
+	require.NoError(t, err)
+
+	require.Equal(t, "map(string)", builder.GetRaw("variable.greeting.type"))
+	value, ok := builder.GetAttribute("variable.greeting.default")
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"text": "Hello ${name}", "directive": "%{if x}"}, value)
+
+	result, err := builder.BuildJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, content, result)
+}
+
+// Test WriteFile() and FromFile() - .json files use the JSON syntax
+func TestWriteFile_JSON(t *testing.T) {
+	builder := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "")
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{
+		"type": "Mesh",
+		"name": hclbuilder.Ref("var.mesh_name"),
+	})
+
+	path := filepath.Join(t.TempDir(), "main.tf.json")
+	require.NoError(t, builder.WriteFile(path))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"name": "${var.mesh_name}"`)
+
+	reloaded, err := hclbuilder.FromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, builder.Build(), reloaded.Build())
+}
+
+// Test FromJSON() - errors
+func TestFromJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"invalid JSON", `{"resource": `, "parsing JSON"},
+		{"trailing data", `{} {}`, "unexpected data after the configuration"},
+		{"not an object", `["resource"]`, "the configuration must be an object"},
+		{"block is not an object", `{"resource": {"kong-mesh_mesh": {"default": "Mesh"}}}`, `resource.kong-mesh_mesh.default: expected an object, got a string`},
+		{"invalid expression", `{"resource": {"kong-mesh_mesh": {"default": {"name": "${var.}"}}}}`, "resource.kong-mesh_mesh.default.name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := hclbuilder.FromJSON(tt.content)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
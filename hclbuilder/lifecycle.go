@@ -0,0 +1,234 @@
+package hclbuilder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// IgnoreAllChanges can be given as the only path of Lifecycle.IgnoreChanges
+// to write ignore_changes = all.
+const IgnoreAllChanges = "all"
+
+// Lifecycle are the settings of the lifecycle block of a resource (see
+// BlockBuilder.Lifecycle). Zero values are not written, leaving the existing
+// setting as is; use RemoveLifecycle to turn a setting off.
+type Lifecycle struct {
+	// IgnoreChanges are the paths of the attributes whose changes are
+	// ignored, in the format of AddAttribute (e.g. "spec.from[0].default").
+	IgnoreChanges []string
+	// PreventDestroy makes plans destroying the resource fail.
+	PreventDestroy bool
+	// CreateBeforeDestroy creates the replacement of the resource before
+	// destroying it.
+	CreateBeforeDestroy bool
+	// ReplaceTriggeredBy replaces the resource when one of these resources
+	// changes.
+	ReplaceTriggeredBy []Addressable
+}
+
+// Lifecycle sets the lifecycle block of the first block in this builder (see
+// BlockBuilder.Lifecycle).
+func (b *Builder) Lifecycle(lifecycle Lifecycle) *Builder {
+	return b.firstBlock().Lifecycle(lifecycle).builder
+}
+
+// Lifecycle sets the given settings in the lifecycle block of this block,
+// creating it if needed. Only the settings given are written: the other
+// attributes of an existing lifecycle block, its precondition and
+// postcondition blocks and its comments are kept. Use RemoveBlock("lifecycle")
+// to remove the block.
+// Example:
+//
+//	policy.Lifecycle(hclbuilder.Lifecycle{
+//	    IgnoreChanges:      []string{"labels", "spec.from[0].default"},
+//	    ReplaceTriggeredBy: []hclbuilder.Addressable{mesh},
+//	})
+//
+// writes
+//
+//	lifecycle {
+//	  ignore_changes       = [labels, spec.from[0].default]
+//	  replace_triggered_by = [kong-mesh_mesh.default]
+//	}
+func (bb *BlockBuilder) Lifecycle(lifecycle Lifecycle) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("Lifecycle", "lifecycle")
+		return bb
+	}
+
+	attributes, err := lifecycleAttributes(lifecycle)
+	if err != nil {
+		bb.addError("Lifecycle", "lifecycle", err)
+		return bb
+	}
+	if len(attributes) == 0 {
+		return bb
+	}
+
+	block := findOrCreateBlock(bb.block.Body(), "lifecycle", nil)
+	for _, attr := range attributes {
+		setAttributeBeforeBlocks(block.Body(), attr.name, attr.tokens)
+	}
+	return bb
+}
+
+// RemoveLifecycle removes settings from the lifecycle block of the first
+// block in this builder (see BlockBuilder.RemoveLifecycle).
+func (b *Builder) RemoveLifecycle(settings ...string) *Builder {
+	return b.firstBlock().RemoveLifecycle(settings...).builder
+}
+
+// RemoveLifecycle removes the given settings, e.g. "create_before_destroy",
+// from the lifecycle block of this block, turning them back to their default:
+// prevent_destroy and create_before_destroy become false, and no changes are
+// ignored or trigger a replacement. The lifecycle block is removed when it
+// becomes empty.
+//
+// Settings that are not lifecycle settings record ErrInvalidPath, and
+// settings that are not set record ErrAttributeNotFound (see Builder.Err).
+// Example: builder.Block("resource.kong-mesh_mesh.default").RemoveLifecycle("prevent_destroy")
+func (bb *BlockBuilder) RemoveLifecycle(settings ...string) *BlockBuilder {
+	bb = bb.mutable()
+	if bb.block == nil {
+		bb.notFound("RemoveLifecycle", "lifecycle")
+		return bb
+	}
+
+	block := findBlock(bb.block.Body(), "lifecycle", nil)
+	for _, setting := range settings {
+		path := "lifecycle." + setting
+		switch {
+		case !slices.Contains(lifecycleSettings, setting):
+			bb.addError("RemoveLifecycle", path, fmt.Errorf("%w: %q is not a lifecycle setting", ErrInvalidPath, setting))
+		case block == nil || block.Body().RemoveAttribute(setting) == nil:
+			bb.addError("RemoveLifecycle", path, ErrAttributeNotFound)
+		}
+	}
+
+	if block != nil && len(block.Body().Attributes()) == 0 && len(block.Body().Blocks()) == 0 {
+		bb.block.Body().RemoveBlock(block)
+	}
+	return bb
+}
+
+// lifecycleSettings are the attributes of a lifecycle block set by Lifecycle.
+var lifecycleSettings = []string{"ignore_changes", "prevent_destroy", "create_before_destroy", "replace_triggered_by"}
+
+// lifecycleAttribute is an attribute of a lifecycle block.
+type lifecycleAttribute struct {
+	name   string
+	tokens hclwrite.Tokens
+}
+
+// lifecycleAttributes returns the attributes of a lifecycle block with the
+// given settings, in the order they are written.
+func lifecycleAttributes(lifecycle Lifecycle) ([]lifecycleAttribute, error) {
+	var attributes []lifecycleAttribute
+
+	if len(lifecycle.IgnoreChanges) == 1 && lifecycle.IgnoreChanges[0] == IgnoreAllChanges {
+		attributes = append(attributes, lifecycleAttribute{"ignore_changes", hclwrite.TokensForIdentifier(IgnoreAllChanges)})
+	} else if len(lifecycle.IgnoreChanges) > 0 {
+		traversals := make([]string, len(lifecycle.IgnoreChanges))
+		for i, path := range lifecycle.IgnoreChanges {
+			traversal, err := attributeTraversal(path)
+			if err != nil {
+				return nil, err
+			}
+			traversals[i] = traversal
+		}
+		tokens, err := exprTokens([]byte("[" + strings.Join(traversals, ", ") + "]"))
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, lifecycleAttribute{"ignore_changes", tokens})
+	}
+
+	if lifecycle.PreventDestroy {
+		attributes = append(attributes, lifecycleAttribute{"prevent_destroy", hclwrite.TokensForValue(cty.True)})
+	}
+	if lifecycle.CreateBeforeDestroy {
+		attributes = append(attributes, lifecycleAttribute{"create_before_destroy", hclwrite.TokensForValue(cty.True)})
+	}
+
+	if len(lifecycle.ReplaceTriggeredBy) > 0 {
+		addresses := make([]string, len(lifecycle.ReplaceTriggeredBy))
+		for i, other := range lifecycle.ReplaceTriggeredBy {
+			addresses[i] = other.Address()
+			if addresses[i] == "" || strings.HasPrefix(addresses[i], "data.") || strings.HasPrefix(addresses[i], "module.") {
+				return nil, fmt.Errorf("%w: replace_triggered_by element %d is not a managed resource block", ErrNotResource, i)
+			}
+		}
+		tokens, err := exprTokens([]byte("[" + strings.Join(addresses, ", ") + "]"))
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, lifecycleAttribute{"replace_triggered_by", tokens})
+	}
+	return attributes, nil
+}
+
+// setAttributeBeforeBlocks sets an attribute of body. An existing attribute
+// keeps its position and comments; a new one is written after the last
+// attribute, before the nested blocks.
+func setAttributeBeforeBlocks(body *hclwrite.Body, name string, tokens hclwrite.Tokens) {
+	if body.GetAttribute(name) != nil || len(body.Blocks()) == 0 {
+		body.SetAttributeRaw(name, tokens)
+		return
+	}
+
+	items := bodyItems(body)
+	insertAt := 0
+	for i, item := range items {
+		if item.attribute != nil {
+			insertAt = i + 1
+		}
+	}
+	i := 0
+	rebuildBody(body, func(item bodyItem) {
+		if i == insertAt {
+			body.SetAttributeRaw(name, tokens)
+		}
+		appendItem(body, item)
+		i++
+	})
+	if i == insertAt {
+		body.SetAttributeRaw(name, tokens)
+	}
+}
+
+// attributeTraversal converts an attribute path in the format of AddAttribute
+// to a relative traversal, e.g. "spec.from[0].default" or `labels["kuma.io/zone"]`.
+// Wildcards are not supported.
+func attributeTraversal(path string) (string, error) {
+	steps, err := parseAttributePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	var traversal strings.Builder
+	for i, step := range steps {
+		switch {
+		case step.wildcard:
+			return "", fmt.Errorf("%w: %q: wildcards are not supported", ErrInvalidPath, path)
+		case step.isIndex:
+			fmt.Fprintf(&traversal, "[%d]", step.index)
+		case i == 0:
+			// The first key of a traversal cannot be indexed
+			if !hclsyntax.ValidIdentifier(step.key) {
+				return "", fmt.Errorf("%w: %q: %q is not an attribute name", ErrInvalidPath, path, step.key)
+			}
+			traversal.WriteString(step.key)
+		case hclsyntax.ValidIdentifier(step.key):
+			traversal.WriteString("." + step.key)
+		default:
+			traversal.WriteString("[" + string(hclwrite.TokensForValue(cty.StringVal(step.key)).Bytes()) + "]")
+		}
+	}
+	return traversal.String(), nil
+}
@@ -0,0 +1,183 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test Lifecycle() - settings, and editing an existing lifecycle block in place
+func TestLifecycle(t *testing.T) {
+	builder, err := hclbuilder.FromString(`resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+
+  lifecycle {
+    prevent_destroy = true
+
+    precondition {
+      condition     = var.mesh_name != ""
+      error_message = "The mesh name must not be empty."
+    }
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+}
+`)
+	require.NoError(t, err)
+	mesh := builder.Block("resource.kong-mesh_mesh.default")
+
+	mesh.Lifecycle(hclbuilder.Lifecycle{
+		IgnoreChanges:       []string{hclbuilder.IgnoreAllChanges},
+		CreateBeforeDestroy: true,
+	})
+	builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all").Lifecycle(hclbuilder.Lifecycle{
+		IgnoreChanges:      []string{"labels", "spec.from[0].default", `labels."kuma.io/zone"`},
+		PreventDestroy:     true,
+		ReplaceTriggeredBy: []hclbuilder.Addressable{mesh},
+	})
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "lifecycle.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test Lifecycle() - settings that are not given are kept, and RemoveBlock removes the block
+func TestLifecycle_Keep(t *testing.T) {
+	builder, err := hclbuilder.FromString(`resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+
+  lifecycle {
+    # Zones are recreated with the mesh
+    create_before_destroy = true # keep
+    ignore_changes        = [labels]
+
+    postcondition {
+      condition     = self.name != ""
+      error_message = "The mesh must have a name."
+    }
+  }
+}
+`)
+	require.NoError(t, err)
+
+	builder.Lifecycle(hclbuilder.Lifecycle{})
+	builder.Lifecycle(hclbuilder.Lifecycle{PreventDestroy: true, IgnoreChanges: []string{"labels", "spec"}})
+	require.NoError(t, builder.Err())
+	require.Equal(t, `resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+
+  lifecycle {
+    # Zones are recreated with the mesh
+    create_before_destroy = true # keep
+    ignore_changes        = [labels, spec]
+    prevent_destroy       = true
+
+    postcondition {
+      condition     = self.name != ""
+      error_message = "The mesh must have a name."
+    }
+  }
+}
+`, builder.Build())
+
+	builder.Block("resource.kong-mesh_mesh.default").RemoveBlock("lifecycle")
+	require.NotContains(t, builder.Build(), "lifecycle")
+	require.NoError(t, builder.Err())
+}
+
+// Test RemoveLifecycle() - settings are turned off one at a time, and the empty block is removed
+func TestRemoveLifecycle(t *testing.T) {
+	builder, err := hclbuilder.FromString(`resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+
+  lifecycle {
+    prevent_destroy       = true
+    create_before_destroy = true
+  }
+}
+`)
+	require.NoError(t, err)
+
+	// Turning off a loaded setting keeps the others
+	builder.RemoveLifecycle("create_before_destroy")
+	require.NoError(t, builder.Err())
+	require.Equal(t, `resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+`, builder.Build())
+
+	// prevent_destroy goes back to false once removed
+	builder.Block("resource.kong-mesh_mesh.default").RemoveLifecycle("prevent_destroy")
+	require.NoError(t, builder.Err())
+	require.Equal(t, `resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+
+}
+`, builder.Build())
+}
+
+// Test RemoveLifecycle() - errors
+func TestRemoveLifecycle_Errors(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.RemoveLifecycle("prevent_destroy")
+	requirePathError(t, builder.Err(), "RemoveLifecycle", "resource.kong-mesh_mesh.default.lifecycle.prevent_destroy", hclbuilder.ErrAttributeNotFound)
+
+	builder = hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.Lifecycle(hclbuilder.Lifecycle{PreventDestroy: true})
+	builder.RemoveLifecycle("type")
+	requirePathError(t, builder.Err(), "RemoveLifecycle", "resource.kong-mesh_mesh.default.lifecycle.type", hclbuilder.ErrInvalidPath)
+	require.Contains(t, builder.Build(), "prevent_destroy = true")
+
+	builder = hclbuilder.New()
+	builder.RemoveLifecycle("prevent_destroy")
+	requirePathError(t, builder.Err(), "RemoveLifecycle", "lifecycle", hclbuilder.ErrBlockNotFound)
+}
+
+// Test Lifecycle() - errors
+func TestLifecycle_Errors(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+
+	builder.Lifecycle(hclbuilder.Lifecycle{IgnoreChanges: []string{"spec.from[*].default"}})
+	requirePathError(t, builder.Err(), "Lifecycle", "resource.kong-mesh_mesh.default.lifecycle", hclbuilder.ErrInvalidPath)
+
+	builder = hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.Lifecycle(hclbuilder.Lifecycle{ReplaceTriggeredBy: []hclbuilder.Addressable{builder.Block("variable.missing")}})
+	requirePathError(t, builder.Err(), "Lifecycle", "resource.kong-mesh_mesh.default.lifecycle", hclbuilder.ErrNotResource)
+	require.NotContains(t, builder.Build(), "lifecycle")
+
+	// Data sources and modules cannot trigger replacements
+	data := builder.AddMeshData("default", "default")
+	module, err := hclbuilder.FromString(`module "networking" {}`)
+	require.NoError(t, err)
+	for _, other := range []hclbuilder.Addressable{data, module} {
+		builder = hclbuilder.New()
+		builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+		builder.Lifecycle(hclbuilder.Lifecycle{ReplaceTriggeredBy: []hclbuilder.Addressable{other}})
+		requirePathError(t, builder.Err(), "Lifecycle", "resource.kong-mesh_mesh.default.lifecycle", hclbuilder.ErrNotResource)
+		require.NotContains(t, builder.Build(), "lifecycle")
+	}
+
+	// The first key of an ignored path must be an attribute name
+	builder = hclbuilder.New()
+	builder.SetBlock("resource.kong-mesh_mesh.default", map[string]any{"type": "Mesh"})
+	builder.Lifecycle(hclbuilder.Lifecycle{IgnoreChanges: []string{`"kuma.io/zone".name`}})
+	requirePathError(t, builder.Err(), "Lifecycle", "resource.kong-mesh_mesh.default.lifecycle", hclbuilder.ErrInvalidPath)
+	require.NotContains(t, builder.Build(), "lifecycle")
+}
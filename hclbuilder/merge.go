@@ -0,0 +1,409 @@
+package hclbuilder
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// MergeStrategy controls how Upsert combines a block with an existing block
+// of the same identity (same type and labels, and same alias for providers).
+type MergeStrategy int
+
+const (
+	// MergeReplace replaces the existing block with the upserted one, at the
+	// existing block's position. The block is shared with the other builder,
+	// so later changes made through it are visible in this builder.
+	MergeReplace MergeStrategy = iota
+	// MergeDeep merges the upserted block into the existing one: attributes
+	// holding objects are merged key by key, other attributes are replaced,
+	// nested blocks are merged by identity and everything only present in the
+	// existing block is kept. Existing blocks shared with a builder upserted
+	// earlier are merged into a copy, leaving that builder unchanged.
+	MergeDeep
+)
+
+// UpsertWith embeds another builder's content into this builder, combining
+// blocks that already exist in this builder according to strategy. Blocks
+// that do not exist yet are appended and shared with the other builder.
+// Top-level attributes are replaced (MergeReplace) or merged (MergeDeep).
+func (b *Builder) UpsertWith(other *Builder, strategy MergeStrategy) *Builder {
+	if other == nil || other.file == nil || other == b {
+		return b
+	}
+	if b.copyOnWrite {
+		// Snapshots must not see later changes made through the other builder
+		b, other = b.mutable(), other.Clone()
+	}
+
+	// Remember the builder to report its errors and to support Remove
+	b.upsertedBuilders.add(other)
+
+	body := b.file.Body()
+	for _, block := range other.file.Body().Blocks() {
+		upsertBlock(body, block, strategy, &b.upsertedBuilders)
+	}
+
+	mergeAttributes(body, other.file.Body(), strategy)
+
+	return b
+}
+
+// upsertBlock appends block to body, or combines it with the block of body
+// with the same identity according to strategy. Blocks shared with the
+// builders in upserted are deep-merged into a copy, so that these builders
+// are left unchanged.
+func upsertBlock(body *hclwrite.Body, block *hclwrite.Block, strategy MergeStrategy, upserted *builderSet) {
+	existing := findBlockByKey(body, blockKey(block))
+	switch {
+	case existing == nil:
+		body.AppendBlock(block)
+	case existing == block:
+		// Already shared with the other builder
+	case strategy == MergeDeep:
+		mergeBlock(unshareBlock(body, existing, upserted), block, upserted)
+	default:
+		replaceBlock(body, existing, block)
+	}
+}
+
+// blockKey returns the identity of a block: its type and labels, the alias
+// of provider blocks, and the address import, moved and removed blocks are
+// about.
+func blockKey(block *hclwrite.Block) string {
+	parts := append([]string{block.Type()}, block.Labels()...)
+	var identifier string
+	switch block.Type() {
+	case "provider":
+		identifier = "alias"
+	case "import":
+		identifier = "to"
+	case "moved", "removed":
+		identifier = "from"
+	}
+	if identifier != "" {
+		if attr := block.Body().GetAttribute(identifier); attr != nil {
+			parts = append(parts, identifier+"="+string(bytes.TrimSpace(exprSource(attr))))
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// findBlockByKey returns the first block of body with the given identity.
+func findBlockByKey(body *hclwrite.Body, key string) *hclwrite.Block {
+	for _, block := range body.Blocks() {
+		if blockKey(block) == key {
+			return block
+		}
+	}
+	return nil
+}
+
+// mergeBlock deep-merges src into dst (see MergeDeep). Nested blocks of dst
+// shared with the builders in upserted are merged into a copy.
+func mergeBlock(dst, src *hclwrite.Block, upserted *builderSet) {
+	mergeAttributes(dst.Body(), src.Body(), MergeDeep)
+
+	for _, block := range src.Body().Blocks() {
+		switch existing := findBlockByKey(dst.Body(), blockKey(block)); existing {
+		case nil:
+			dst.Body().AppendBlock(block)
+		case block:
+			// Already shared
+		default:
+			mergeBlock(unshareBlock(dst.Body(), existing, upserted), block, upserted)
+		}
+	}
+}
+
+// unshareBlock returns block if it does not belong to any builder in
+// upserted, or replaces it in body with a copy and returns the copy otherwise.
+func unshareBlock(body *hclwrite.Body, block *hclwrite.Block, upserted *builderSet) *hclwrite.Block {
+	if !upserted.shares(block) {
+		return block
+	}
+	copied, err := copyBlock(block)
+	if err != nil {
+		return block
+	}
+	replaceBlock(body, block, copied)
+	return copied
+}
+
+// mergeAttributes sets the attributes of src on dst, in source order. With
+// MergeDeep, attributes holding objects on both sides are merged key by key.
+func mergeAttributes(dst, src *hclwrite.Body, strategy MergeStrategy) {
+	for _, name := range attributeNames(src) {
+		tokens := src.GetAttribute(name).Expr().BuildTokens(nil)
+		if existing := dst.GetAttribute(name); existing != nil && strategy == MergeDeep {
+			merged := mergeObjectSource(exprSource(existing), tokens.Bytes())
+			if mergedTokens, err := exprTokens(merged); err == nil {
+				tokens = mergedTokens
+			}
+		}
+		dst.SetAttributeRaw(name, tokens)
+	}
+}
+
+// mergeObjectSource merges the object constructor src into the object
+// constructor dst, recursively. If either is not an object constructor, src
+// wins.
+func mergeObjectSource(dst, src []byte) []byte {
+	dstExpr, err := parseExpr(dst)
+	if err != nil {
+		return src
+	}
+	srcExpr, err := parseExpr(src)
+	if err != nil {
+		return src
+	}
+	if _, ok := dstExpr.(*hclsyntax.ObjectConsExpr); !ok {
+		return src
+	}
+	srcObj, ok := srcExpr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return src
+	}
+
+	merged := dst
+	for _, item := range srcObj.Items {
+		key, ok := objectItemKey(item)
+		if !ok {
+			return src
+		}
+		rng := item.ValueExpr.Range()
+		value := src[rng.Start.Byte:rng.End.Byte]
+		edited, err := editNested(merged, []pathStep{{key: key}}, func(existing []byte) ([]byte, error) {
+			if existing == nil {
+				return value, nil
+			}
+			return mergeObjectSource(existing, value), nil
+		})
+		if err != nil {
+			return src
+		}
+		merged = edited
+	}
+	return merged
+}
+
+// attributeNames returns the names of the attributes of body in source order.
+func attributeNames(body *hclwrite.Body) []string {
+	var names []string
+	for _, item := range bodyItems(body) {
+		if item.attribute != nil {
+			names = append(names, item.name)
+		}
+	}
+	return names
+}
+
+// bodyItem is a segment of a body's tokens: a block, an attribute, or
+// unstructured tokens (comments and blank lines) between them.
+type bodyItem struct {
+	block     *hclwrite.Block
+	attribute *hclwrite.Attribute
+	name      string
+	tokens    hclwrite.Tokens
+}
+
+// bodyItems splits a body into its items in source order. hclwrite only
+// exposes attributes as a map, so the order is recovered from the identity of
+// the tokens each item is made of.
+func bodyItems(body *hclwrite.Body) []bodyItem {
+	starts := make(map[*hclwrite.Token]bodyItem)
+	for _, block := range body.Blocks() {
+		if tokens := block.BuildTokens(nil); len(tokens) > 0 {
+			starts[tokens[0]] = bodyItem{block: block, tokens: tokens}
+		}
+	}
+	for name, attr := range body.Attributes() {
+		if tokens := attr.BuildTokens(nil); len(tokens) > 0 {
+			starts[tokens[0]] = bodyItem{attribute: attr, name: name, tokens: tokens}
+		}
+	}
+
+	var items []bodyItem
+	var unstructured hclwrite.Tokens
+	tokens := body.BuildTokens(nil)
+	for i := 0; i < len(tokens); {
+		item, ok := starts[tokens[i]]
+		if !ok {
+			unstructured = append(unstructured, tokens[i])
+			i++
+			continue
+		}
+		if len(unstructured) > 0 {
+			items = append(items, bodyItem{tokens: unstructured})
+			unstructured = nil
+		}
+		items = append(items, item)
+		i += len(item.tokens)
+	}
+	if len(unstructured) > 0 {
+		items = append(items, bodyItem{tokens: unstructured})
+	}
+	return items
+}
+
+// replaceBlock puts replacement in place of old in body. Blocks keep their
+// identity, so builders sharing them still see their changes. The comments
+// above old are kept above replacement unless it has its own.
+func replaceBlock(body *hclwrite.Body, old, replacement *hclwrite.Block) {
+	rebuildBody(body, func(item bodyItem) {
+		if item.block != old {
+			appendItem(body, item)
+			return
+		}
+		// Keep the comments describing the block
+		if lead := leadComments(item.tokens); len(lead) > 0 && len(leadComments(replacement.BuildTokens(nil))) == 0 {
+			body.AppendUnstructuredTokens(lead)
+		}
+		body.AppendBlock(replacement)
+	})
+}
+
+// prependBlock puts block at the top of body, followed by a blank line.
+func prependBlock(body *hclwrite.Body, block *hclwrite.Block) {
+	first := true
+	rebuildBody(body, func(item bodyItem) {
+		if first {
+			body.AppendBlock(block)
+			body.AppendNewline()
+			first = false
+		}
+		appendItem(body, item)
+	})
+	if first {
+		body.AppendBlock(block)
+	}
+}
+
+// rebuildBody detaches every item of body and calls emit for each of them in
+// order, to append them again. hclwrite cannot insert an item at a given
+// position, so this is how items are inserted or replaced in place.
+func rebuildBody(body *hclwrite.Body, emit func(item bodyItem)) {
+	items := bodyItems(body)
+
+	// Detach every item before clearing, so the body does not keep stale items
+	for _, item := range items {
+		switch {
+		case item.block != nil:
+			body.RemoveBlock(item.block)
+		case item.attribute != nil:
+			body.RemoveAttribute(item.name)
+		}
+	}
+	body.Clear()
+
+	for _, item := range items {
+		emit(item)
+	}
+}
+
+// appendItem appends an item detached by rebuildBody to body. Blocks keep
+// their identity; attributes are recreated with their comments.
+func appendItem(body *hclwrite.Body, item bodyItem) {
+	switch {
+	case item.block != nil:
+		body.AppendBlock(item.block)
+	case item.attribute != nil:
+		if lead := leadComments(item.tokens); len(lead) > 0 {
+			body.AppendUnstructuredTokens(lead)
+		}
+		appendAttribute(body, item)
+	default:
+		body.AppendUnstructuredTokens(item.tokens)
+	}
+}
+
+// appendAttribute appends an attribute detached by rebuildBody to body,
+// without its lead comments. The comment at the end of its line is kept.
+func appendAttribute(body *hclwrite.Body, item bodyItem) {
+	tokens := item.attribute.Expr().BuildTokens(nil)
+	// The comment is part of the expression if the attribute was appended before
+	if comment := lineComment(item.tokens); comment != nil && comment != lineComment(tokens) {
+		tokens = append(tokens, &hclwrite.Token{
+			Type:         hclsyntax.TokenComment,
+			Bytes:        bytes.TrimRight(comment.Bytes, "\n"),
+			SpacesBefore: 1,
+		})
+	}
+	body.SetAttributeRaw(item.name, tokens)
+}
+
+// lineComment returns the comment an attribute's tokens end with, if any.
+func lineComment(tokens hclwrite.Tokens) *hclwrite.Token {
+	i := len(tokens) - 1
+	for i >= 0 && tokens[i].Type == hclsyntax.TokenNewline {
+		i--
+	}
+	if i < 0 || tokens[i].Type != hclsyntax.TokenComment {
+		return nil
+	}
+	return tokens[i]
+}
+
+// leadComments returns the comment tokens an item's tokens start with.
+func leadComments(tokens hclwrite.Tokens) hclwrite.Tokens {
+	var lead hclwrite.Tokens
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			break
+		}
+		lead = append(lead, token)
+	}
+	return lead
+}
+
+// builderSet is the set of builders upserted into a builder or a module, in
+// the order they were first upserted, so that their errors are always
+// reported in the same order.
+type builderSet struct {
+	order   []*Builder
+	members map[*Builder]bool
+}
+
+func (s *builderSet) add(b *Builder) {
+	if s.members[b] {
+		return
+	}
+	if s.members == nil {
+		s.members = make(map[*Builder]bool)
+	}
+	s.members[b] = true
+	s.order = append(s.order, b)
+}
+
+func (s *builderSet) remove(b *Builder) {
+	if !s.members[b] {
+		return
+	}
+	delete(s.members, b)
+	for i, member := range s.order {
+		if member == b {
+			s.order = append(s.order[:i:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// list returns the builders in the order they were added.
+func (s *builderSet) list() []*Builder {
+	return s.order
+}
+
+// shares reports whether block belongs to one of the builders in the set.
+func (s *builderSet) shares(block *hclwrite.Block) bool {
+	if s == nil {
+		return false
+	}
+	for _, b := range s.order {
+		if _, ok := blockIndices(b.file.Body(), block); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test Upsert() - a block with the same identity is replaced in place
+func TestUpsert_ReplaceInPlace(t *testing.T) {
+	mainFile, err := hclbuilder.FromFile(filepath.Join("testdata", "upsert-main.input.tf"))
+	require.NoError(t, err)
+	other, err := hclbuilder.FromFile(filepath.Join("testdata", "upsert-other.input.tf"))
+	require.NoError(t, err)
+
+	mainFile.Upsert(other)
+
+	result, err := mainFile.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "upsert-replace.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	// The replacement block is shared with the other builder
+	other.AddAttribute("description", hclbuilder.Literal("shared"))
+	require.Contains(t, mainFile.Build(), `description = "shared"`)
+
+	// Upserting again changes nothing
+	mainFile.Upsert(other)
+	require.Equal(t, 1, strings.Count(mainFile.Build(), `resource "konnect_mesh" "m"`))
+}
+
+// Test UpsertWith() - MergeDeep merges attributes, objects and nested blocks
+func TestUpsertWith_MergeDeep(t *testing.T) {
+	mainFile, err := hclbuilder.FromFile(filepath.Join("testdata", "upsert-main.input.tf"))
+	require.NoError(t, err)
+	other, err := hclbuilder.FromFile(filepath.Join("testdata", "upsert-other.input.tf"))
+	require.NoError(t, err)
+
+	mainFile.UpsertWith(other, hclbuilder.MergeDeep)
+
+	result, err := mainFile.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "upsert-merge-deep.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	// Merging again is idempotent
+	mainFile.UpsertWith(other, hclbuilder.MergeDeep)
+	require.Equal(t, result, mainFile.Build())
+}
+
+// Test UpsertWith() - MergeDeep leaves builders upserted earlier unchanged
+func TestUpsertWith_MergeDeepShared(t *testing.T) {
+	a, err := hclbuilder.FromString(`
+resource "konnect_mesh" "m" {
+  name = "a"
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+`)
+	require.NoError(t, err)
+	b, err := hclbuilder.FromString(`
+resource "konnect_mesh" "m" {
+  description = "b"
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`)
+	require.NoError(t, err)
+	before := a.Build()
+
+	mainFile := hclbuilder.New()
+	mainFile.Upsert(a)
+	mainFile.UpsertWith(b, hclbuilder.MergeDeep)
+
+	require.Equal(t, before, a.Build())
+	result, err := mainFile.BuildE()
+	require.NoError(t, err)
+	require.Contains(t, result, `name = "a"`)
+	require.Contains(t, result, `description = "b"`)
+	require.Contains(t, result, "prevent_destroy       = true")
+	require.Contains(t, result, "create_before_destroy = true")
+}
+
+// Test Remove() - every block kind and top-level attributes contributed by the other builder are removed
+func TestRemove_AllBlockKinds(t *testing.T) {
+	mainFile, err := hclbuilder.FromFile(filepath.Join("testdata", "upsert-main.input.tf"))
+	require.NoError(t, err)
+	before := mainFile.Build()
+
+	other, err := hclbuilder.FromFile(filepath.Join("testdata", "remove-builder-other.input.tf"))
+	require.NoError(t, err)
+
+	mainFile.Upsert(other)
+	require.Contains(t, mainFile.Build(), `module "networking"`)
+	require.Contains(t, mainFile.Build(), "mesh_count")
+
+	mainFile.Remove(other)
+
+	result, err := mainFile.BuildE()
+	require.NoError(t, err)
+	require.Equal(t, before, result)
+}
@@ -0,0 +1,356 @@
+package hclbuilder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultModuleFile is the file of a Module that blocks are written to when
+// their type has no route (see Module.Route).
+const DefaultModuleFile = "main.tf"
+
+// defaultRoutes are the files blocks are written to by type, following the
+// usual layout of Terraform modules.
+var defaultRoutes = map[string]string{
+	"terraform": "versions.tf",
+	"variable":  "variables.tf",
+	"output":    "outputs.tf",
+}
+
+// Module is a Terraform module made of several .tf files, each one held by a
+// Builder. Blocks are looked up across all the files, and new blocks are
+// written to a file chosen by block type: terraform blocks to versions.tf,
+// variables to variables.tf, outputs to outputs.tf and everything else to
+// main.tf, unless routed otherwise (see Route). Use File to work on a
+// specific file.
+//
+// Example:
+//
+//	module, err := hclbuilder.LoadModule("examples/mesh")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	module.SetBlock("variable.mesh_name", map[string]any{"default": "default"})  // variables.tf
+//	module.Block("resource.kong-mesh_mesh.default").AddAttribute("name", "var.mesh_name")
+//	err = module.WriteDir("examples/mesh")
+type Module struct {
+	files            map[string]*Builder
+	routes           map[string]string
+	upsertedBuilders builderSet
+	// detached holds the errors recorded through BlockBuilders of missing
+	// blocks, which belong to no file.
+	detached *Builder
+	errs     []error
+}
+
+// NewModule creates an empty module.
+func NewModule() *Module {
+	routes := make(map[string]string, len(defaultRoutes))
+	for blockType, file := range defaultRoutes {
+		routes[blockType] = file
+	}
+	return &Module{
+		files:    make(map[string]*Builder),
+		routes:   routes,
+		detached: New(),
+	}
+}
+
+// LoadModule loads the .tf files of a directory, without descending into
+// subdirectories. Files in JSON syntax (.tf.json) are not loaded, since the
+// files of a module are built and written as HCL; load them with FromFile.
+func LoadModule(dir string) (*Module, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading module directory: %w", err)
+	}
+
+	m := NewModule()
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".tf" {
+			continue
+		}
+		builder, err := FromFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", entry.Name(), err)
+		}
+		m.files[entry.Name()] = builder
+	}
+	return m, nil
+}
+
+// Route sets the file new blocks of the given type are written to.
+// Example: module.Route("provider", "providers.tf").
+func (m *Module) Route(blockType, file string) *Module {
+	m.routes[blockType] = file
+	return m
+}
+
+// File returns the builder of a file of the module, creating it if it does
+// not exist. Changes made through it are part of the module.
+func (m *Module) File(name string) *Builder {
+	builder, ok := m.files[name]
+	if !ok {
+		builder = New()
+		m.files[name] = builder
+	}
+	return builder
+}
+
+// Files returns the names of the files of the module, sorted.
+func (m *Module) Files() []string {
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Block returns a BlockBuilder for the block at the given path (see
+// Builder.Block), in whichever file it is. Looking up a missing block does
+// not create any file.
+func (m *Module) Block(path string) *BlockBuilder {
+	parts, err := splitBlockPath(path)
+	if err != nil {
+		return m.detached.Block(path)
+	}
+	if file := m.fileWithBlock(parts[0], parts[1:]); file != nil {
+		return file.Block(path)
+	}
+	return m.detached.Block(path)
+}
+
+// SetBlock creates or replaces a block (see Builder.SetBlock). An existing
+// block is replaced in its file; a new block is written to the file routed
+// for its type.
+func (m *Module) SetBlock(path string, attributes map[string]any) *Module {
+	parts, err := splitTopLevelBlockPath(path)
+	if err != nil {
+		m.addError("SetBlock", path, err)
+		return m
+	}
+	m.writeBlock(parts[0], parts[1:], func(file *Builder) {
+		file.SetBlock(path, attributes)
+	})
+	return m
+}
+
+// SetAttribute sets an attribute value at the given path (see
+// Builder.SetAttribute), in the file of the block it belongs to.
+func (m *Module) SetAttribute(path string, value any) *Module {
+	parts, err := splitBlockPath(path)
+	if err != nil {
+		m.addError("SetAttribute", path, err)
+		return m
+	}
+	labelCount, _ := blockLabelCount(parts[0])
+	if len(parts) < labelCount+2 {
+		m.addError("SetAttribute", path, fmt.Errorf("%w: expected at least %d parts (block_type, %d label(s) and attribute_name), got %d", ErrInvalidPath, labelCount+2, labelCount, len(parts)))
+		return m
+	}
+	m.writeBlock(parts[0], parts[1:labelCount+1], func(file *Builder) {
+		file.SetAttribute(path, value)
+	})
+	return m
+}
+
+// RemoveBlock removes the block at the given path from the file it is in.
+// If the block does not exist, an error is recorded (see Err).
+func (m *Module) RemoveBlock(path string) *Module {
+	parts, err := splitTopLevelBlockPath(path)
+	if err != nil {
+		m.addError("RemoveBlock", path, err)
+		return m
+	}
+	for _, name := range m.Files() {
+		if removeBlock(m.files[name].file.Body(), parts[0], parts[1:]) {
+			return m
+		}
+	}
+	m.addError("RemoveBlock", path, ErrBlockNotFound)
+	return m
+}
+
+// Upsert embeds another builder's blocks into the module (see Builder.Upsert).
+// Blocks that already exist are replaced in their file; other blocks are
+// appended to the file routed for their type.
+func (m *Module) Upsert(other *Builder) *Module {
+	return m.UpsertWith(other, MergeReplace)
+}
+
+// UpsertWith embeds another builder's blocks into the module, combining
+// blocks that already exist according to strategy (see Builder.UpsertWith).
+// Top-level attributes are written to the default file.
+func (m *Module) UpsertWith(other *Builder, strategy MergeStrategy) *Module {
+	if other == nil || other.file == nil {
+		return m
+	}
+	if other.copyOnWrite {
+		other = other.Clone()
+	}
+	m.upsertedBuilders.add(other)
+
+	for _, block := range other.file.Body().Blocks() {
+		file := m.fileWithBlockKey(blockKey(block))
+		if file == nil {
+			file = m.File(m.route(block.Type()))
+		}
+		upsertBlock(file.file.Body(), block, strategy, &m.upsertedBuilders)
+	}
+	if len(other.file.Body().Attributes()) > 0 {
+		mergeAttributes(m.File(DefaultModuleFile).file.Body(), other.file.Body(), strategy)
+	}
+	return m
+}
+
+// GetAttribute returns the value of the attribute at the given path (see
+// Builder.GetAttribute), in whichever file its block is.
+func (m *Module) GetAttribute(path string) (any, bool) {
+	for _, name := range m.Files() {
+		if value, ok := m.files[name].GetAttribute(path); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// GetRaw returns the source text of the attribute at the given path (see
+// Builder.GetRaw), in whichever file its block is.
+func (m *Module) GetRaw(path string) string {
+	for _, name := range m.Files() {
+		if raw := m.files[name].GetRaw(path); raw != "" {
+			return raw
+		}
+	}
+	return ""
+}
+
+// Blocks returns the top-level blocks of the given type across all files,
+// in file name order then source order (see Builder.Blocks).
+func (m *Module) Blocks(blockType string) []BlockRef {
+	var refs []BlockRef
+	for _, name := range m.Files() {
+		refs = append(refs, m.files[name].Blocks(blockType)...)
+	}
+	return refs
+}
+
+// Resources returns the addresses of the resources and data sources across
+// all files, in file name order then source order.
+func (m *Module) Resources() []string {
+	var addresses []string
+	for _, name := range m.Files() {
+		addresses = append(addresses, m.files[name].Resources()...)
+	}
+	return addresses
+}
+
+// Build returns the content of every file of the module, by file name.
+func (m *Module) Build() map[string]string {
+	result := make(map[string]string, len(m.files))
+	for name, builder := range m.files {
+		result[name] = builder.Build()
+	}
+	return result
+}
+
+// Err returns the errors recorded by operations on the module, on its files
+// and on the builders upserted into it (see Builder.Err).
+func (m *Module) Err() error {
+	errs := append([]error(nil), m.errs...)
+	seen := make(map[*Builder]bool)
+	errs = append(errs, m.detached.collectErrors(seen)...)
+	for _, name := range m.Files() {
+		errs = append(errs, m.files[name].collectErrors(seen)...)
+	}
+	for _, other := range m.upsertedBuilders.list() {
+		errs = append(errs, other.collectErrors(seen)...)
+	}
+	return errors.Join(errs...)
+}
+
+// WriteDir writes the files of the module to dir, creating it if needed, in
+// file name order. Files without content are only written if they already
+// exist in dir, so that a file whose blocks were all removed is emptied.
+func (m *Module) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating module directory: %w", err)
+	}
+	for _, name := range m.Files() {
+		path := filepath.Join(dir, name)
+		content := m.files[name].file.Bytes()
+		if len(strings.TrimSpace(string(content))) == 0 {
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// route returns the file new blocks of the given type are written to.
+func (m *Module) route(blockType string) string {
+	if file, ok := m.routes[blockType]; ok {
+		return file
+	}
+	return DefaultModuleFile
+}
+
+// fileWithBlock returns the builder of the file containing the block with
+// the given type and labels, or nil.
+func (m *Module) fileWithBlock(blockType string, labels []string) *Builder {
+	for _, name := range m.Files() {
+		if findBlock(m.files[name].file.Body(), blockType, labels) != nil {
+			return m.files[name]
+		}
+	}
+	return nil
+}
+
+// writeBlock calls write with the builder of the file a block with the given
+// type and labels is written to: the file containing it, or the file routed
+// for the type. A file that does not exist yet is only added to the module if
+// write adds a block to it; otherwise its errors are recorded on the module.
+func (m *Module) writeBlock(blockType string, labels []string, write func(file *Builder)) {
+	if file := m.fileWithBlock(blockType, labels); file != nil {
+		write(file)
+		return
+	}
+	name := m.route(blockType)
+	if file, ok := m.files[name]; ok {
+		write(file)
+		return
+	}
+
+	file := New()
+	write(file)
+	if len(file.file.Body().Blocks()) > 0 {
+		m.files[name] = file
+		return
+	}
+	m.errs = append(m.errs, file.errs...)
+}
+
+// fileWithBlockKey returns the builder of the file containing a block with
+// the given identity (see blockKey), or nil.
+func (m *Module) fileWithBlockKey(key string) *Builder {
+	for _, name := range m.Files() {
+		if findBlockByKey(m.files[name].file.Body(), key) != nil {
+			return m.files[name]
+		}
+	}
+	return nil
+}
+
+// addError records a failed operation on the module.
+func (m *Module) addError(op, path string, err error) {
+	m.errs = append(m.errs, &PathError{Op: op, Path: path, Err: err})
+}
@@ -0,0 +1,149 @@
+package hclbuilder_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// assertGoldenDir compares every file written to dir with the golden file of
+// the same name, prefixed with prefix
+func assertGoldenDir(t *testing.T, dir, prefix string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		goldenFile := filepath.Join("testdata", prefix+strings.TrimSuffix(entry.Name(), ".tf")+".golden.tf")
+		assertGoldenFile(t, goldenFile, string(content))
+	}
+}
+
+// Test LoadModule() - lookups across files
+func TestLoadModule(t *testing.T) {
+	module, err := hclbuilder.LoadModule(filepath.Join("testdata", "module"))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"main.tf", "outputs.tf", "variables.tf"}, module.Files())
+	require.Equal(t, []string{"kong-mesh_mesh.default"}, module.Resources())
+	require.Len(t, module.Blocks(""), 4)
+	require.Equal(t, "variable.mesh_name", module.Blocks("variable")[0].Path)
+
+	value, ok := module.GetAttribute("variable.mesh_name.default")
+	require.True(t, ok)
+	require.Equal(t, "default", value)
+	require.Equal(t, "kong-mesh_mesh.default.name", module.GetRaw("output.mesh_name.value"))
+	require.True(t, module.Block("resource.kong-mesh_mesh.default").Exists())
+	require.False(t, module.Block("resource.kong-mesh_mesh.other").Exists())
+
+	_, err = hclbuilder.LoadModule(filepath.Join("testdata", "missing"))
+	require.Error(t, err)
+}
+
+// Test Module - blocks are routed to files by type, and existing blocks are edited in place
+func TestModule_Routing(t *testing.T) {
+	module, err := hclbuilder.LoadModule(filepath.Join("testdata", "module"))
+	require.NoError(t, err)
+
+	policy, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+}
+
+output "policy_name" {
+  value = kong-mesh_mesh_traffic_permission.allow_all.name
+}
+`)
+	require.NoError(t, err)
+
+	module.Route("provider", "providers.tf").
+		SetBlock("variable.zone", map[string]any{"default": "eu"}).
+		SetBlock(`provider."kong-mesh"`, map[string]any{"server_url": "http://localhost:5682"}).
+		SetBlock("provider.konnect", map[string]any{"server_url": "https://global.api.konghq.com"}).
+		SetAttribute("terraform.required_version", ">= 1.5").
+		SetAttribute("variable.mesh_name.description", "Name of the mesh").
+		Upsert(policy)
+	module.Block("resource.kong-mesh_mesh.default").AddAttribute("skip_creating_initial_policies", `["*"]`)
+	module.File("locals.tf").SetAttribute("locals.zone", hclbuilder.Ref("var.zone"))
+	require.NoError(t, module.Err())
+
+	dir := t.TempDir()
+	require.NoError(t, module.WriteDir(dir))
+	assertGoldenDir(t, dir, "module-routing-")
+
+	// Writing again gives the same files
+	first := module.Build()
+	reloaded, err := hclbuilder.LoadModule(dir)
+	require.NoError(t, err)
+	require.Equal(t, first, reloaded.Build())
+}
+
+// Test Module - removing blocks across files
+func TestModule_RemoveBlock(t *testing.T) {
+	module, err := hclbuilder.LoadModule(filepath.Join("testdata", "module"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, module.WriteDir(dir))
+
+	module.RemoveBlock("output.mesh_name").RemoveBlock("output.missing")
+	requirePathError(t, module.Err(), "RemoveBlock", "output.missing", hclbuilder.ErrBlockNotFound)
+
+	// Emptied files are written so that removed blocks do not linger
+	require.NoError(t, module.WriteDir(dir))
+	content, err := os.ReadFile(filepath.Join(dir, "outputs.tf"))
+	require.NoError(t, err)
+	require.Empty(t, string(content))
+
+	// Files created but left empty are not written
+	module.File("unused.tf")
+	require.NoError(t, module.WriteDir(dir))
+	require.NoFileExists(t, filepath.Join(dir, "unused.tf"))
+}
+
+// Test Module - looking up missing blocks does not create files
+func TestModule_LookupMissingBlock(t *testing.T) {
+	module := hclbuilder.NewModule()
+
+	require.False(t, module.Block("resource.kong-mesh_mesh.default").Exists())
+	module.Block("resource.kong-mesh_mesh.default").AddAttribute("name", `"mesh-1"`)
+	module.Block(`resource.kong-mesh_mesh."default`).AddAttribute("name", `"mesh-1"`)
+
+	require.Empty(t, module.Files())
+	requirePathError(t, module.Err(), "AddAttribute", "resource.kong-mesh_mesh.default.name", hclbuilder.ErrBlockNotFound)
+	require.ErrorIs(t, module.Err(), hclbuilder.ErrInvalidPath)
+
+	// Failed writes do not create files either
+	module = hclbuilder.NewModule()
+	module.SetAttribute("resource.kong-mesh_mesh.default.name", make(chan int))
+	require.Empty(t, module.Files())
+	requirePathError(t, module.Err(), "SetAttribute", "resource.kong-mesh_mesh.default.name", hclbuilder.ErrUnsupportedValue)
+	module = hclbuilder.NewModule()
+	module.SetBlock("variable.zone", map[string]any{"default": make(chan int)})
+	require.Empty(t, module.Files())
+	requirePathError(t, module.Err(), "SetBlock", "variable.zone", hclbuilder.ErrUnsupportedValue)
+
+	// Writing a block creates its file
+	module.SetAttribute("resource.kong-mesh_mesh.default.name", "mesh-1")
+	require.Equal(t, []string{"main.tf"}, module.Files())
+}
+
+// Test LoadModule() - files in JSON syntax are not loaded
+func TestLoadModule_SkipsJSON(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "zone" {}`+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "override.tf.json"), []byte(`{"variable": {"region": {}}}`), 0o600))
+
+	module, err := hclbuilder.LoadModule(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"main.tf"}, module.Files())
+	require.Len(t, module.Blocks("variable"), 1)
+}
@@ -0,0 +1,431 @@
+package hclbuilder
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Nested attributes are edited on the source of the root attribute's
+// expression rather than on its evaluated value: the expression is parsed
+// with hclsyntax to locate the items of object constructors, and only the
+// bytes of the targeted item are replaced. Everything else (references,
+// function calls, conditionals, heredocs and comments) is kept verbatim.
+
+// exprSource returns the source of an attribute's expression.
+func exprSource(attr *hclwrite.Attribute) []byte {
+	return attr.Expr().BuildTokens(nil).Bytes()
+}
+
+// exprTokens parses src as an expression and returns its tokens, ready to be
+// used with hclwrite.Body.SetAttributeRaw.
+func exprTokens(src []byte) (hclwrite.Tokens, error) {
+	wrapped := append([]byte("dummy = "), bytes.TrimSpace(src)...)
+	wrapped = append(wrapped, '\n')
+
+	file, diags := hclwrite.ParseConfig(wrapped, "<expr>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing expression: %s", diags.Error())
+	}
+	return file.Body().GetAttribute("dummy").Expr().BuildTokens(nil), nil
+}
+
+// parseExpr parses src as a native syntax expression. A newline is appended
+// so that a heredoc ending src is terminated; ranges still refer to src.
+func parseExpr(src []byte) (hclsyntax.Expression, error) {
+	src = append(src[:len(src):len(src)], '\n')
+	expr, diags := hclsyntax.ParseExpression(src, "<expr>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing expression: %s", diags.Error())
+	}
+	return expr, nil
+}
+
+// objectItemKey returns the key of an object constructor item, if it is static.
+func objectItemKey(item hclsyntax.ObjectConsItem) (string, bool) {
+	if keyword := hcl.ExprAsKeyword(item.KeyExpr); keyword != "" {
+		return keyword, true
+	}
+	val, diags := item.KeyExpr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || !val.Type().Equals(cty.String) {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// findObjectItem returns the item of obj with the given key.
+func findObjectItem(obj *hclsyntax.ObjectConsExpr, key string) (hclsyntax.ObjectConsItem, bool) {
+	for _, item := range obj.Items {
+		if itemKey, ok := objectItemKey(item); ok && itemKey == key {
+			return item, true
+		}
+	}
+	return hclsyntax.ObjectConsItem{}, false
+}
+
+// objectKeySource returns the source of an object key, quoting it if it is not an identifier.
+func objectKeySource(key string) []byte {
+	if hclsyntax.ValidIdentifier(key) {
+		return []byte(key)
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key)).Bytes()
+}
+
+// editFunc returns the new source of an expression given its current source,
+// which is nil if the expression does not exist yet.
+type editFunc func(src []byte) ([]byte, error)
+
+// replaceWith returns an editFunc replacing the expression with value.
+func replaceWith(value []byte) editFunc {
+	return func([]byte) ([]byte, error) {
+		return value, nil
+	}
+}
+
+// editNested returns src with the expression at steps replaced by the result
+// of edit. src is nil if the expression does not exist yet. Missing object
+// keys along the path are created, in place of a null value too. Other values
+// that are not object constructors, such as references or function calls,
+// are never replaced: an error wrapping ErrInvalidExpression is returned.
+// List indices must refer to existing elements.
+func editNested(src []byte, steps []pathStep, edit editFunc) ([]byte, error) {
+	if len(steps) == 0 {
+		return edit(src)
+	}
+	step := steps[0]
+
+	var expr hclsyntax.Expression
+	if src != nil {
+		var err error
+		if expr, err = parseExpr(src); err != nil {
+			return nil, err
+		}
+	}
+
+	if !step.isKey() {
+		if expr == nil {
+			return nil, fmt.Errorf("%w: no list to apply %s to", ErrAttributeNotFound, step)
+		}
+		tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not a list", ErrAttributeNotFound, step)
+		}
+		elems, err := selectElements(tuple, step)
+		if err != nil {
+			return nil, err
+		}
+		// Edit from the last element so that earlier byte offsets stay valid
+		for i := len(elems) - 1; i >= 0; i-- {
+			rng := elems[i].Range()
+			value, err := editNested(src[rng.Start.Byte:rng.End.Byte], steps[1:], edit)
+			if err != nil {
+				return nil, err
+			}
+			src = splice(src, rng.Start.Byte, rng.End.Byte, bytes.TrimSpace(value))
+		}
+		return src, nil
+	}
+
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		if expr != nil && !isNull(expr) {
+			return nil, fmt.Errorf("%w: cannot set %s: %s is not an object", ErrInvalidExpression, step, bytes.TrimSpace(src))
+		}
+		value, err := editNested(nil, steps[1:], edit)
+		if err != nil {
+			return nil, err
+		}
+		return objectSource(step.key, value), nil
+	}
+
+	if item, ok := findObjectItem(obj, step.key); ok {
+		rng := item.ValueExpr.Range()
+		value, err := editNested(src[rng.Start.Byte:rng.End.Byte], steps[1:], edit)
+		if err != nil {
+			return nil, err
+		}
+		return splice(src, rng.Start.Byte, rng.End.Byte, bytes.TrimSpace(value)), nil
+	}
+
+	value, err := editNested(nil, steps[1:], edit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects on a single line get the new item on the same line
+	closing := obj.SrcRange.End.Byte - 1
+	if len(obj.Items) > 0 && !bytes.Contains(src[obj.SrcRange.Start.Byte:closing], []byte("\n")) {
+		item := append(append(objectKeySource(step.key), " = "...), bytes.TrimSpace(value)...)
+		end := obj.Items[len(obj.Items)-1].ValueExpr.Range().End.Byte
+		if i := skipSpaces(src, end); src[i] == ',' {
+			return splice(src, i+1, i+1, append(append([]byte(" "), item...), ',')), nil
+		}
+		return splice(src, end, end, append([]byte(", "), item...)), nil
+	}
+
+	// Append a new item right before the closing brace
+	var item bytes.Buffer
+	if !bytes.HasSuffix(bytes.TrimRight(src[:closing], " \t"), []byte("\n")) {
+		item.WriteByte('\n')
+	}
+	item.Write(objectKeySource(step.key))
+	item.WriteString(" = ")
+	item.Write(bytes.TrimSpace(value))
+	item.WriteByte('\n')
+	return splice(src, closing, closing, item.Bytes()), nil
+}
+
+// isNull reports whether expr is the null literal.
+func isNull(expr hclsyntax.Expression) bool {
+	literal, ok := expr.(*hclsyntax.LiteralValueExpr)
+	return ok && literal.Val.IsNull()
+}
+
+// objectSource returns the source of an object with a single item.
+func objectSource(key string, value []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	buf.Write(objectKeySource(key))
+	buf.WriteString(" = ")
+	buf.Write(bytes.TrimSpace(value))
+	buf.WriteString("\n}")
+	return buf.Bytes()
+}
+
+// selectElements returns the elements of tuple selected by an index or
+// wildcard step. Like an out of range index, a wildcard on an empty list
+// selects nothing and is an error.
+func selectElements(tuple *hclsyntax.TupleConsExpr, step pathStep) ([]hclsyntax.Expression, error) {
+	if step.wildcard {
+		if len(tuple.Exprs) == 0 {
+			return nil, fmt.Errorf("%w: %s on an empty list", ErrAttributeNotFound, step)
+		}
+		return tuple.Exprs, nil
+	}
+	if step.index >= len(tuple.Exprs) {
+		return nil, fmt.Errorf("%w: index %d out of range (length %d)", ErrAttributeNotFound, step.index, len(tuple.Exprs))
+	}
+	return tuple.Exprs[step.index : step.index+1], nil
+}
+
+// insertElement returns an editFunc inserting value into a list before the
+// element at index, or appending it if index is negative. A missing list is
+// created.
+func insertElement(index int, value []byte) editFunc {
+	value = bytes.TrimSpace(value)
+	return func(src []byte) ([]byte, error) {
+		if src == nil {
+			if index > 0 {
+				return nil, fmt.Errorf("%w: index %d out of range (length 0)", ErrAttributeNotFound, index)
+			}
+			return append(append([]byte("["), value...), ']'), nil
+		}
+
+		expr, err := parseExpr(src)
+		if err != nil {
+			return nil, err
+		}
+		tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+		if !ok {
+			return nil, fmt.Errorf("%w: not a list", ErrAttributeNotFound)
+		}
+
+		elems := tuple.Exprs
+		if index > len(elems) {
+			return nil, fmt.Errorf("%w: index %d out of range (length %d)", ErrAttributeNotFound, index, len(elems))
+		}
+		if len(elems) == 0 {
+			opening := tuple.SrcRange.Start.Byte + 1
+			return splice(src, opening, tuple.SrcRange.End.Byte-1, value), nil
+		}
+
+		if index >= 0 && index < len(elems) {
+			// Insert before the element, on a line of its own if the element has one
+			start := elems[index].Range().Start.Byte
+			lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+			if len(bytes.TrimSpace(src[lineStart:start])) == 0 {
+				return splice(src, lineStart, lineStart, append(value, ",\n"...)), nil
+			}
+			return splice(src, start, start, append(value, ", "...)), nil
+		}
+
+		// Append after the last element (and its trailing comma, if any)
+		end := elems[len(elems)-1].Range().End.Byte
+		multiline := bytes.Contains(src[end:tuple.SrcRange.End.Byte], []byte("\n"))
+		comma := false
+		if i := skipSpaces(src, end); src[i] == ',' {
+			end, comma = i+1, true
+		}
+		if !multiline {
+			if comma {
+				return splice(src, end, end, append(append([]byte(" "), value...), ',')), nil
+			}
+			return splice(src, end, end, append([]byte(", "), value...)), nil
+		}
+
+		// On a line of its own, after the line comment of the last element
+		lineEnd := end
+		if i := skipSpaces(src, end); isLineComment(src[i:]) {
+			lineEnd = i + bytes.IndexByte(src[i:], '\n')
+		}
+		if comma {
+			return splice(src, lineEnd, lineEnd, append(append([]byte("\n"), value...), ',')), nil
+		}
+		src = splice(src, lineEnd, lineEnd, append([]byte("\n"), value...))
+		return splice(src, end, end, []byte(",")), nil
+	}
+}
+
+// removeNested returns src with the value at steps removed. It reports
+// whether the path was found, and whether the removal left the object at src
+// empty, in which case the caller should remove it as well. Lists are never
+// reported as empty: removing their last element leaves an empty list, and
+// list elements that become empty objects are kept as {}.
+func removeNested(src []byte, steps []pathStep) ([]byte, bool, bool, error) {
+	expr, err := parseExpr(src)
+	if err != nil {
+		return nil, false, false, err
+	}
+	step := steps[0]
+
+	if !step.isKey() {
+		tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+		if !ok {
+			return src, false, false, nil
+		}
+		elems, err := selectElements(tuple, step)
+		if err != nil {
+			return src, false, false, nil
+		}
+
+		found := false
+		for i := len(elems) - 1; i >= 0; i-- {
+			rng := elems[i].Range()
+			if len(steps) == 1 {
+				start, end := elementExtent(src, tuple, elems[i])
+				src = splice(src, start, end, nil)
+				found = true
+				continue
+			}
+			modified, elemFound, empty, err := removeNested(src[rng.Start.Byte:rng.End.Byte], steps[1:])
+			if err != nil {
+				return nil, false, false, err
+			}
+			if !elemFound {
+				continue
+			}
+			found = true
+			if empty {
+				modified = []byte("{}")
+			}
+			src = splice(src, rng.Start.Byte, rng.End.Byte, bytes.TrimSpace(modified))
+		}
+		return src, found, false, nil
+	}
+
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return src, false, false, nil
+	}
+	item, ok := findObjectItem(obj, step.key)
+	if !ok {
+		return src, false, false, nil
+	}
+
+	if len(steps) > 1 {
+		rng := item.ValueExpr.Range()
+		modified, found, empty, err := removeNested(src[rng.Start.Byte:rng.End.Byte], steps[1:])
+		if err != nil || !found {
+			return src, found, false, err
+		}
+		if !empty {
+			return splice(src, rng.Start.Byte, rng.End.Byte, bytes.TrimSpace(modified)), true, false, nil
+		}
+	}
+
+	start, end := itemExtent(src, item.KeyExpr.Range().Start.Byte, item.ValueExpr.Range().End.Byte)
+	return splice(src, start, end, nil), true, len(obj.Items) == 1, nil
+}
+
+// elementExtent returns the byte range to remove for a list element. The
+// last element of a single-line list takes the preceding comma with it.
+func elementExtent(src []byte, tuple *hclsyntax.TupleConsExpr, elem hclsyntax.Expression) (int, int) {
+	rng := elem.Range()
+	start, end := itemExtent(src, rng.Start.Byte, rng.End.Byte)
+
+	last := len(tuple.Exprs) - 1
+	if tuple.Exprs[last] == elem && last > 0 && src[end-1] != ',' && src[end-1] != '\n' {
+		start = tuple.Exprs[last-1].Range().End.Byte
+	}
+	return start, end
+}
+
+// itemExtent widens the byte range of a collection item so that removing it
+// also removes its separating comma, its line comment, its lead comments and,
+// when the item is on lines of its own, those whole lines.
+func itemExtent(src []byte, start, end int) (int, int) {
+	// Trailing comma and line comment
+	i := skipSpaces(src, end)
+	if i < len(src) && src[i] == ',' {
+		end = i + 1
+		i = skipSpaces(src, end)
+		if i < len(src) && src[i] != '\n' && !isLineComment(src[i:]) {
+			// Another item follows on the same line
+			end = i
+		}
+	}
+	if isLineComment(src[i:]) {
+		if nl := bytes.IndexByte(src[i:], '\n'); nl >= 0 {
+			i += nl
+		} else {
+			i = len(src)
+		}
+		end = i
+	}
+	if i < len(src) && src[i] == '\n' {
+		end = i + 1
+	}
+
+	// Only remove whole lines if the item starts its own line
+	lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+	if len(bytes.TrimSpace(src[lineStart:start])) != 0 || src[end-1] != '\n' {
+		return start, end
+	}
+	start = lineStart
+
+	// Lead comments on the lines right above the item
+	for start > 0 {
+		prevStart := bytes.LastIndexByte(src[:start-1], '\n') + 1
+		if !isLineComment(bytes.TrimSpace(src[prevStart:start])) {
+			break
+		}
+		start = prevStart
+	}
+
+	return start, end
+}
+
+// isLineComment reports whether src starts with a line comment.
+func isLineComment(src []byte) bool {
+	return bytes.HasPrefix(src, []byte("#")) || bytes.HasPrefix(src, []byte("//"))
+}
+
+// skipSpaces returns the index of the first byte at or after i that is not a space or tab.
+func skipSpaces(src []byte, i int) int {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// splice returns a copy of src with the bytes in [start, end) replaced by repl.
+func splice(src []byte, start, end int, repl []byte) []byte {
+	result := make([]byte, 0, len(src)-(end-start)+len(repl))
+	result = append(result, src[:start]...)
+	result = append(result, repl...)
+	return append(result, src[end:]...)
+}
@@ -0,0 +1,177 @@
+package hclbuilder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a single step of an attribute path: an object key, a list
+// index or a wildcard matching every element of a list.
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func (s pathStep) isKey() bool {
+	return !s.isIndex && !s.wildcard
+}
+
+func (s pathStep) String() string {
+	switch {
+	case s.wildcard:
+		return "[*]"
+	case s.isIndex:
+		return fmt.Sprintf("[%d]", s.index)
+	default:
+		return s.key
+	}
+}
+
+// pathSegment is a dot-separated segment of a path. Quoted segments are
+// unescaped, and the text following their closing quote (e.g. a list index)
+// is kept as suffix.
+type pathSegment struct {
+	text   string
+	quoted bool
+	suffix string
+}
+
+// splitPath splits a path on dots. A segment can be double-quoted to contain
+// dots, with \" and \\ escapes: resource."konnect_mesh"."a.b".
+func splitPath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for i := 0; ; i++ {
+		var segment pathSegment
+		if i < len(path) && path[i] == '"' {
+			var text strings.Builder
+			for i++; ; i++ {
+				if i >= len(path) {
+					return nil, fmt.Errorf("%w: unterminated quote in %q", ErrInvalidPath, path)
+				}
+				if path[i] == '"' {
+					break
+				}
+				if path[i] == '\\' && i+1 < len(path) && (path[i+1] == '"' || path[i+1] == '\\') {
+					i++
+				}
+				text.WriteByte(path[i])
+			}
+			end := strings.IndexByte(path[i+1:], '.')
+			if end < 0 {
+				end = len(path) - i - 1
+			}
+			segment = pathSegment{text: text.String(), quoted: true, suffix: path[i+1 : i+1+end]}
+			i += 1 + end
+		} else {
+			end := strings.IndexByte(path[i:], '.')
+			if end < 0 {
+				end = len(path) - i
+			}
+			segment = pathSegment{text: path[i : i+end]}
+			i += end
+		}
+
+		if (!segment.quoted && strings.ContainsRune(segment.text, '"')) || strings.ContainsRune(segment.suffix, '"') {
+			return nil, fmt.Errorf("%w: unexpected quote in %q", ErrInvalidPath, path)
+		}
+		if segment.text == "" && !segment.quoted {
+			return nil, fmt.Errorf("%w: empty segment in %q", ErrInvalidPath, path)
+		}
+		segments = append(segments, segment)
+
+		if i >= len(path) {
+			return segments, nil
+		}
+	}
+}
+
+// splitBlockPath splits a block path such as "resource.konnect_mesh.m" into
+// the block type and labels (see splitPath for quoting).
+func splitBlockPath(path string) ([]string, error) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		if segment.suffix != "" {
+			return nil, fmt.Errorf("%w: unexpected %q after quoted segment in %q", ErrInvalidPath, segment.suffix, path)
+		}
+		parts[i] = segment.text
+	}
+	return parts, nil
+}
+
+// JoinPath joins path segments, quoting those that contain dots or other
+// special characters, so that any label can be used in a path.
+// Example: JoinPath("provider", "kong-mesh.eu") returns `provider."kong-mesh.eu"`.
+func JoinPath(parts ...string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if part == "" || strings.ContainsAny(part, ".\"\\[] \t\n") {
+			part = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(part) + `"`
+		}
+		quoted[i] = part
+	}
+	return strings.Join(quoted, ".")
+}
+
+// parseAttributePath parses an attribute path such as
+// "spec.from[0].target_ref.proxy_types" or "spec.from[*].default.action".
+//
+// Keys are separated by dots and may be followed by any number of "[n]"
+// list indices or "[*]" wildcards. Keys containing dots must be quoted:
+// spec.tags."kuma.io/service". The first step is always a key: the name of
+// the attribute in the block.
+func parseAttributePath(path string) ([]pathStep, error) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return attributeSteps(segments, path)
+}
+
+// attributeSteps returns the steps of the attribute path made of segments.
+// path is the full path, used in errors.
+func attributeSteps(segments []pathSegment, path string) ([]pathStep, error) {
+	var steps []pathStep
+	for _, segment := range segments {
+		key, rest := segment.text, segment.suffix
+		if !segment.quoted {
+			key, rest, _ = strings.Cut(segment.text, "[")
+			if rest != "" {
+				rest = "[" + rest
+			}
+		}
+		if !segment.quoted && (key == "" || strings.Contains(key, "]")) {
+			return nil, fmt.Errorf("%w: invalid key %q in %q", ErrInvalidPath, key, path)
+		}
+		steps = append(steps, pathStep{key: key})
+		if rest == "" {
+			continue
+		}
+		if !strings.HasPrefix(rest, "[") {
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidPath, rest, path)
+		}
+
+		for _, index := range strings.Split(rest, "[")[1:] {
+			index, ok := strings.CutSuffix(index, "]")
+			if !ok {
+				return nil, fmt.Errorf("%w: unterminated index in %q", ErrInvalidPath, path)
+			}
+			if index == "*" {
+				steps = append(steps, pathStep{wildcard: true})
+				continue
+			}
+			n, err := strconv.Atoi(index)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%w: index %q in %q is not a non-negative integer", ErrInvalidPath, index, path)
+			}
+			steps = append(steps, pathStep{index: n, isIndex: true})
+		}
+	}
+	return steps, nil
+}
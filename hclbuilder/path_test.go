@@ -0,0 +1,143 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test SetBlock(), Block() and RemoveBlock() - labels containing dots
+func TestPath_QuotedLabels(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock(`provider."kong-mesh.eu"`, map[string]any{
+		"kong_mesh_server_url": "https://eu.example.com",
+	})
+	builder.SetBlock(`resource.konnect_mesh."a.b"`, map[string]any{
+		"name": "a.b",
+	})
+	builder.SetBlock(hclbuilder.JoinPath("resource", "konnect_mesh", "c.d"), map[string]any{
+		"name": "c.d",
+	})
+	builder.Block(`resource."konnect_mesh"."a.b"`).AddAttribute("description", `"first"`)
+	builder.RemoveBlock(`resource.konnect_mesh."c.d"`)
+
+	require.True(t, builder.Block(`provider."kong-mesh.eu"`).Exists())
+	require.Equal(t, "konnect_mesh.a.b", builder.Block(`resource.konnect_mesh."a.b"`).ResourcePath())
+	require.False(t, builder.Block(`resource.konnect_mesh."c.d"`).Exists())
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "path-quoted-labels.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test SetAttribute() - labels containing dots
+func TestPath_SetAttributeQuotedLabel(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetAttribute(`provider."kong-mesh.eu".alias`, "eu")
+	builder.SetAttribute(`resource.konnect_mesh."a.b".name`, "a.b")
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	require.Contains(t, result, "provider \"kong-mesh.eu\" {\n  alias = \"eu\"\n}")
+	require.Contains(t, result, "resource \"konnect_mesh\" \"a.b\" {\n  name = \"a.b\"\n}")
+}
+
+// Test AddAttribute() and RemoveAttribute() - quoted keys containing dots
+func TestPath_QuotedAttributeKeys(t *testing.T) {
+	builder, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh" "default" {
+  name = "mesh-1"
+}
+`)
+	require.NoError(t, err)
+
+	builder.AddAttribute(`tags."kuma.io/service"`, `"backend"`)
+	builder.AddAttribute(`tags."kuma.io/zone"`, `"eu"`)
+	builder.RemoveAttribute(`tags."kuma.io/zone"`)
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	require.Contains(t, result, `"kuma.io/service" = "backend"`)
+	require.NotContains(t, result, "kuma.io/zone")
+}
+
+// Test RemoveAttribute() - a quoted top-level attribute name is unquoted
+func TestPath_RemoveQuotedAttribute(t *testing.T) {
+	builder, err := hclbuilder.FromString(`
+resource "kong-mesh_mesh" "default" {
+  name       = "mesh-1"
+  quoted-key = "value"
+}
+`)
+	require.NoError(t, err)
+
+	builder.RemoveAttribute(`"quoted-key"`)
+	builder.RemoveAttribute(`"missing"`)
+
+	requirePathError(t, builder.Err(), "RemoveAttribute", `resource.kong-mesh_mesh.default."missing"`, hclbuilder.ErrAttributeNotFound)
+	require.NotContains(t, builder.Build(), "quoted-key")
+	require.Contains(t, builder.Build(), `name = "mesh-1"`)
+}
+
+// Test JoinPath()
+func TestJoinPath(t *testing.T) {
+	tests := map[string]struct {
+		parts    []string
+		expected string
+	}{
+		"plain":       {[]string{"resource", "konnect_mesh", "m"}, "resource.konnect_mesh.m"},
+		"dot":         {[]string{"provider", "kong-mesh.eu"}, `provider."kong-mesh.eu"`},
+		"quote":       {[]string{"resource", "t", `a"b`}, `resource.t."a\"b"`},
+		"backslash":   {[]string{"resource", "t", `a\b`}, `resource.t."a\\b"`},
+		"brackets":    {[]string{"spec", "a[0]"}, `spec."a[0]"`},
+		"empty label": {[]string{"resource", "t", ""}, `resource.t.""`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := hclbuilder.JoinPath(tc.parts...)
+			require.Equal(t, tc.expected, path)
+
+			// The joined path finds the block with these labels
+			builder := hclbuilder.New()
+			builder.SetBlock(path, map[string]any{})
+			require.NoError(t, builder.Err())
+			if tc.parts[0] == "resource" {
+				require.True(t, builder.Block(path).Exists())
+			}
+		})
+	}
+}
+
+// Test invalid paths
+func TestErr_InvalidQuotedPath(t *testing.T) {
+	tests := map[string]string{
+		"unterminated quote": `resource.konnect_mesh."a.b`,
+		"stray quote":        `resource.konnect_mesh.a"b`,
+		"empty segment":      `resource..m`,
+		"suffix on label":    `resource.konnect_mesh."m"[0]`,
+	}
+	for name, path := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := hclbuilder.New()
+			builder.SetBlock(path, map[string]any{"name": "m"})
+			requirePathError(t, builder.Err(), "SetBlock", path, hclbuilder.ErrInvalidPath)
+
+			builder = hclbuilder.New()
+			builder.Block(path).AddAttribute("name", `"m"`)
+			require.ErrorIs(t, builder.Err(), hclbuilder.ErrInvalidPath)
+		})
+	}
+}
+
+// Test errors - block paths with dotted labels are quoted
+func TestErr_QuotedLabelPath(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.SetBlock(`resource.konnect_mesh."a.b"`, map[string]any{"name": "a.b"})
+	builder.Block(`resource.konnect_mesh."a.b"`).RemoveAttribute("missing")
+
+	requirePathError(t, builder.Err(), "RemoveAttribute", `resource.konnect_mesh."a.b".missing`, hclbuilder.ErrAttributeNotFound)
+}
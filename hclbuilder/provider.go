@@ -0,0 +1,148 @@
+package hclbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ProviderOptions are the settings of a Kong Mesh or Konnect provider block
+// (see WithProviderOptions). Empty settings are not written, so that the
+// provider falls back to its defaults and environment variables.
+type ProviderOptions struct {
+	// Alias names an additional configuration of the provider. Resources
+	// added with AddPolicy and AddControlPlane refer to it (see UseProvider).
+	Alias string
+	// ServerURL is the URL of the Kong Mesh control plane or of the Konnect API.
+	ServerURL string
+	// PersonalAccessToken is a Konnect personal access token.
+	PersonalAccessToken string
+	// SystemAccountAccessToken is a Konnect system account access token.
+	SystemAccountAccessToken string
+	// KonnectAccessToken is a Konnect access token.
+	KonnectAccessToken string
+	// HTTPHeaders are custom headers sent with every request.
+	HTTPHeaders map[string]string
+	// Attributes are other settings of the provider, such as retries, written
+	// like SetBlock attributes. They take precedence over the fields above,
+	// e.g. to read a token from a variable:
+	// Attributes: map[string]any{"konnect_access_token": hclbuilder.Ref("var.konnect_token")}
+	Attributes map[string]any
+}
+
+// WithProviderOptions adds a provider block with the given settings, or
+// replaces the one with the same alias in place. The provider becomes the
+// ProviderType of the builder, and resources added afterwards with AddPolicy
+// and AddControlPlane refer to this configuration of the provider.
+// Example:
+//
+//	builder.WithProviderOptions(hclbuilder.Konnect, hclbuilder.ProviderOptions{
+//	    Alias:       "eu",
+//	    ServerURL:   "https://eu.api.konghq.com",
+//	    HTTPHeaders: map[string]string{"X-Team": "mesh"},
+//	})
+//
+// writes
+//
+//	provider "konnect" {
+//	  alias = "eu"
+//	  http_headers = {
+//	    X-Team = "mesh"
+//	  }
+//	  server_url = "https://eu.api.konghq.com"
+//	}
+func (b *Builder) WithProviderOptions(provider ProviderType, opts ProviderOptions) *Builder {
+	b = b.mutable()
+	path := JoinPath("provider", string(provider))
+	if opts.Alias != "" && !hclsyntax.ValidIdentifier(opts.Alias) {
+		b.addError("WithProviderOptions", path, fmt.Errorf("%w: %q is not a valid provider alias", ErrInvalidPath, opts.Alias))
+		return b
+	}
+
+	attributes := make(map[string]any, len(opts.Attributes)+5)
+	for key, value := range map[string]string{
+		"server_url":                  opts.ServerURL,
+		"personal_access_token":       opts.PersonalAccessToken,
+		"system_account_access_token": opts.SystemAccountAccessToken,
+		"konnect_access_token":        opts.KonnectAccessToken,
+	} {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+	if len(opts.HTTPHeaders) > 0 {
+		headers := make(map[string]any, len(opts.HTTPHeaders))
+		for name, value := range opts.HTTPHeaders {
+			headers[name] = value
+		}
+		attributes["http_headers"] = headers
+	}
+	for key, value := range opts.Attributes {
+		attributes[key] = value
+	}
+	delete(attributes, "alias")
+
+	// The alias goes first, as it identifies the block
+	block := hclwrite.NewBlock("provider", []string{string(provider)})
+	if opts.Alias != "" {
+		block.Body().SetAttributeValue("alias", cty.StringVal(opts.Alias))
+	}
+	if err := setBlockAttributes(block.Body(), attributes); err != nil {
+		b.addError("WithProviderOptions", path, err)
+		return b
+	}
+
+	upsertBlock(b.file.Body(), block, MergeReplace, nil)
+	b.ProviderType = provider
+	return b.UseProvider(provider, opts.Alias)
+}
+
+// UseProvider sets the configuration of the provider that resources and data
+// sources added afterwards with AddPolicy, AddControlPlane and the data source
+// helpers (e.g. AddMeshData) refer to: the one with the given alias, or the
+// default one if alias is empty.
+// Example: builder.UseProvider(hclbuilder.KongMesh, "eu") makes AddPolicy
+// write provider = kong-mesh.eu.
+func (b *Builder) UseProvider(provider ProviderType, alias string) *Builder {
+	b = b.mutable()
+	if b.providerAliases == nil {
+		b.providerAliases = make(map[ProviderType]string)
+	}
+	if alias == "" {
+		delete(b.providerAliases, providerKey(provider))
+	} else {
+		b.providerAliases[providerKey(provider)] = alias
+	}
+	return b
+}
+
+// ProviderRef returns a reference to the configuration of the provider used
+// for new resources (see UseProvider), e.g. kong-mesh or kong-mesh.eu, to be
+// used as the provider meta-argument.
+// Example: builder.SetAttribute("resource.kong-mesh_mesh.default.provider", builder.ProviderRef(hclbuilder.KongMesh))
+func (b *Builder) ProviderRef(provider ProviderType) Expression {
+	key := providerKey(provider)
+	if alias, ok := b.providerAliases[key]; ok {
+		return Ref(string(key) + "." + alias)
+	}
+	return Ref(string(key))
+}
+
+// addProviderRef sets the provider meta-argument in attrs if resources of
+// ProviderProperty refer to an aliased configuration (see UseProvider). It is
+// used by every helper adding resources or data sources, so that they all
+// refer to the same configuration.
+func (b *Builder) addProviderRef(attrs map[string]any) {
+	if _, ok := b.providerAliases[providerKey(b.ProviderProperty)]; ok {
+		attrs["provider"] = b.ProviderRef(b.ProviderProperty)
+	}
+}
+
+// providerKey returns the provider name the configuration in use is recorded
+// under: provider names are lowercase in Terraform.
+func providerKey(provider ProviderType) ProviderType {
+	return ProviderType(strings.ToLower(string(provider)))
+}
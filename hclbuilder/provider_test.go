@@ -0,0 +1,104 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test WithProviderOptions() - aliased providers and references from new resources
+func TestWithProviderOptions(t *testing.T) {
+	builder := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "http://localhost:5681")
+	builder.ProviderProperty = hclbuilder.KongMesh
+
+	builder.WithProviderOptions(hclbuilder.KongMesh, hclbuilder.ProviderOptions{
+		Alias:       "eu",
+		ServerURL:   "http://eu.example.com:5681",
+		HTTPHeaders: map[string]string{"X-Zone": "eu"},
+	})
+	builder.AddPolicy("mesh_traffic_permission", "allow-all", "allow_all_eu", "kong-mesh_mesh.default.name", nil)
+
+	// Back to the default configuration
+	builder.UseProvider(hclbuilder.KongMesh, "")
+	builder.AddPolicy("mesh_traffic_permission", "allow-all", "allow_all", "kong-mesh_mesh.default.name", nil)
+
+	// Replacing the default provider keeps the aliased one
+	builder.WithProvider(hclbuilder.KongMesh, "http://localhost:5682")
+	require.Len(t, builder.Blocks("provider"), 2)
+
+	builder.ProviderProperty = hclbuilder.Konnect
+	builder.WithProviderOptions(hclbuilder.Konnect, hclbuilder.ProviderOptions{
+		Alias:               "us",
+		PersonalAccessToken: "kpat_placeholder",
+		Attributes: map[string]any{
+			"personal_access_token": hclbuilder.Ref("var.konnect_token"),
+			"server_url":            "https://us.api.konghq.com",
+		},
+	})
+	builder.AddControlPlane("cp1", "my-control-plane", "Test control plane")
+	require.Equal(t, hclbuilder.Konnect, builder.ProviderType)
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "provider-options.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test WithProviderOptions() - an aliased provider is replaced in place
+func TestWithProviderOptions_Replace(t *testing.T) {
+	builder := hclbuilder.New().
+		WithProviderOptions(hclbuilder.KongMesh, hclbuilder.ProviderOptions{Alias: "eu", ServerURL: "http://eu.example.com:5681"}).
+		WithProvider(hclbuilder.KongMesh, "").
+		WithProviderOptions(hclbuilder.KongMesh, hclbuilder.ProviderOptions{Alias: "eu", KonnectAccessToken: "token"})
+
+	blocks := builder.Blocks("provider")
+	require.Len(t, blocks, 2)
+	value, ok := builder.GetAttribute(`provider."kong-mesh".alias`)
+	require.True(t, ok)
+	require.Equal(t, "eu", value, "the first provider block is the aliased one")
+	require.NotContains(t, builder.Build(), "eu.example.com")
+	require.Contains(t, builder.Build(), `konnect_access_token = "token"`)
+
+	builder.WithProviderOptions(hclbuilder.KongMesh, hclbuilder.ProviderOptions{Alias: "not valid"})
+	requirePathError(t, builder.Err(), "WithProviderOptions", "provider.kong-mesh", hclbuilder.ErrInvalidPath)
+}
+
+// Test ProviderRef() and Clone() - the provider in use is copied
+func TestProviderRef(t *testing.T) {
+	builder := hclbuilder.New().UseProvider(hclbuilder.KongMesh, "eu")
+	clone := builder.Clone()
+	builder.UseProvider(hclbuilder.KongMesh, "")
+
+	clone.SetAttribute("resource.kong-mesh_mesh.default.provider", clone.ProviderRef(hclbuilder.KongMesh))
+	require.Equal(t, "kong-mesh.eu", clone.GetRaw("resource.kong-mesh_mesh.default.provider"))
+	builder.SetAttribute("resource.kong-mesh_mesh.default.provider", builder.ProviderRef(hclbuilder.KongMesh))
+	require.Equal(t, "kong-mesh", builder.GetRaw("resource.kong-mesh_mesh.default.provider"))
+}
+
+// Test UseProvider() - policies, control planes and data sources refer to the same configuration
+func TestUseProvider_SharedReference(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.ProviderProperty = "Kong-Mesh"
+
+	// The default configuration: only policies name the provider, as a lowercase reference
+	builder.AddPolicy("mesh_traffic_permission", "allow-all", "allow_all", "kong-mesh_mesh.default.name", nil)
+	builder.AddControlPlane("cp", "cp", "")
+	builder.AddMeshData("default", "default")
+	require.Equal(t, "kong-mesh", builder.GetRaw("resource.Kong-Mesh_mesh_traffic_permission.allow_all.provider"))
+	require.Contains(t, builder.Build(), "provider = kong-mesh\n")
+	require.Empty(t, builder.GetRaw("resource.Kong-Mesh_mesh_control_plane.cp.provider"))
+	require.Empty(t, builder.GetRaw("data.Kong-Mesh_mesh.default.provider"))
+
+	// An aliased configuration is referenced by all of them
+	builder.UseProvider(hclbuilder.KongMesh, "eu")
+	builder.AddPolicy("mesh_traffic_permission", "allow-all", "allow_all_eu", "kong-mesh_mesh.default.name", nil)
+	builder.AddControlPlane("cp_eu", "cp-eu", "")
+	builder.AddMeshData("default_eu", "default")
+	require.Equal(t, "kong-mesh.eu", builder.GetRaw("resource.Kong-Mesh_mesh_traffic_permission.allow_all_eu.provider"))
+	require.Equal(t, "kong-mesh.eu", builder.GetRaw("resource.Kong-Mesh_mesh_control_plane.cp_eu.provider"))
+	require.Equal(t, "kong-mesh.eu", builder.GetRaw("data.Kong-Mesh_mesh.default_eu.provider"))
+	require.NoError(t, builder.Err())
+}
@@ -0,0 +1,232 @@
+package hclbuilder
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// BlockRef describes a top-level block of a builder.
+type BlockRef struct {
+	// Type of the block, e.g. "resource".
+	Type string
+	// Labels of the block, e.g. []string{"konnect_mesh", "default"}.
+	Labels []string
+	// Path of the block in the format accepted by Block, SetBlock and RemoveBlock.
+	Path string
+	// Address of the block (see BlockBuilder.Address), empty for blocks that
+	// are not resources, data sources or modules.
+	Address string
+}
+
+// Blocks returns the top-level blocks of the given type in source order, or
+// every top-level block if blockType is empty.
+func (b *Builder) Blocks(blockType string) []BlockRef {
+	var refs []BlockRef
+	for _, block := range b.file.Body().Blocks() {
+		if blockType != "" && block.Type() != blockType {
+			continue
+		}
+		refs = append(refs, BlockRef{
+			Type:    block.Type(),
+			Labels:  block.Labels(),
+			Path:    blockPath(block),
+			Address: (&BlockBuilder{block: block}).Address(),
+		})
+	}
+	return refs
+}
+
+// Resources returns the addresses of the resources and data sources in this
+// builder, in source order.
+// Example: []string{"kong-mesh_mesh.default", "data.konnect_mesh_control_plane.cp"}.
+func (b *Builder) Resources() []string {
+	var addresses []string
+	for _, block := range b.file.Body().Blocks() {
+		if block.Type() != "resource" && block.Type() != "data" {
+			continue
+		}
+		if address := (&BlockBuilder{block: block}).Address(); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// GetAttribute returns the value of the attribute at the given path, in the
+// format accepted by SetAttribute. Nested attributes and list elements can be
+// read as with AddAttribute: "resource.kong-mesh_mesh.default.routing.zone_egress".
+//
+// Literals are returned as Go values: string, bool, int64 or float64 for
+// numbers, []any for lists (empty, not nil, for []) and map[string]any for
+// objects. Expressions that cannot be evaluated without context, such as
+// references and function calls, are returned as Raw values holding their
+// source text (see GetRaw), so that they are not mistaken for strings.
+//
+// Returns false if the path is invalid or the attribute does not exist.
+func (b *Builder) GetAttribute(path string) (any, bool) {
+	expr, src, ok := b.lookupAttribute(path)
+	if !ok {
+		return nil, false
+	}
+	return exprValue(expr, src), true
+}
+
+// GetRaw returns the source text of the attribute at the given path (see
+// GetAttribute), e.g. `kong-mesh_mesh.default.name` or `"mesh-1"`.
+// Returns empty string if the path is invalid or the attribute does not exist.
+func (b *Builder) GetRaw(path string) string {
+	expr, src, ok := b.lookupAttribute(path)
+	if !ok {
+		return ""
+	}
+	return exprText(expr, src)
+}
+
+// GetAttribute returns the value of the attribute at the given path, relative
+// to this block (see Builder.GetAttribute).
+// Example: builder.Block("resource.kong-mesh_mesh.default").GetAttribute("routing.zone_egress").
+func (bb *BlockBuilder) GetAttribute(path string) (any, bool) {
+	expr, src, ok := bb.lookupAttribute(path)
+	if !ok {
+		return nil, false
+	}
+	return exprValue(expr, src), true
+}
+
+// GetRaw returns the source text of the attribute at the given path, relative
+// to this block (see Builder.GetRaw).
+func (bb *BlockBuilder) GetRaw(path string) string {
+	expr, src, ok := bb.lookupAttribute(path)
+	if !ok {
+		return ""
+	}
+	return exprText(expr, src)
+}
+
+// lookupAttribute resolves a path in the format accepted by SetAttribute:
+// the block type and its labels, nested blocks, then the attribute path.
+func (b *Builder) lookupAttribute(path string) (hclsyntax.Expression, []byte, bool) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	labelCount, _ := blockLabelCount(segments[0].text)
+	if len(segments) < labelCount+2 {
+		return nil, nil, false
+	}
+	parts := make([]string, labelCount+1)
+	for i, segment := range segments[:labelCount+1] {
+		if segment.suffix != "" {
+			return nil, nil, false
+		}
+		parts[i] = segment.text
+	}
+
+	block := findBlock(b.file.Body(), parts[0], parts[1:])
+	if block == nil {
+		return nil, nil, false
+	}
+
+	// Descend into nested blocks, unless an attribute has the same name
+	rest := segments[labelCount+1:]
+	for len(rest) > 1 && rest[0].suffix == "" && block.Body().GetAttribute(rest[0].text) == nil {
+		nested := findBlock(block.Body(), rest[0].text, nil)
+		if nested == nil {
+			break
+		}
+		block, rest = nested, rest[1:]
+	}
+
+	steps, err := attributeSteps(rest, path)
+	if err != nil {
+		return nil, nil, false
+	}
+	return lookupSteps(block.Body(), steps)
+}
+
+// lookupAttribute resolves an attribute path relative to this block.
+func (bb *BlockBuilder) lookupAttribute(path string) (hclsyntax.Expression, []byte, bool) {
+	if bb.block == nil {
+		return nil, nil, false
+	}
+	steps, err := parseAttributePath(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	return lookupSteps(bb.block.Body(), steps)
+}
+
+// lookupSteps returns the expression at steps in body, along with the source
+// it was parsed from. Wildcards are not supported.
+func lookupSteps(body *hclwrite.Body, steps []pathStep) (hclsyntax.Expression, []byte, bool) {
+	attr := body.GetAttribute(steps[0].key)
+	if attr == nil {
+		return nil, nil, false
+	}
+	src := exprSource(attr)
+	expr, err := parseExpr(src)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	for _, step := range steps[1:] {
+		switch e := expr.(type) {
+		case *hclsyntax.ObjectConsExpr:
+			if !step.isKey() {
+				return nil, nil, false
+			}
+			item, ok := findObjectItem(e, step.key)
+			if !ok {
+				return nil, nil, false
+			}
+			expr = item.ValueExpr
+		case *hclsyntax.TupleConsExpr:
+			if !step.isIndex || step.index >= len(e.Exprs) {
+				return nil, nil, false
+			}
+			expr = e.Exprs[step.index]
+		default:
+			return nil, nil, false
+		}
+	}
+	return expr, src, true
+}
+
+// exprText returns the source text of expr.
+func exprText(expr hclsyntax.Expression, src []byte) string {
+	rng := expr.Range()
+	return string(bytes.TrimSpace(src[rng.Start.Byte:rng.End.Byte]))
+}
+
+// exprValue returns the Go value of expr (see Builder.GetAttribute). Objects
+// and lists are converted item by item, so that a reference in an object
+// only affects the value of its own item.
+func exprValue(expr hclsyntax.Expression, src []byte) any {
+	if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+		return convertCtyToGo(val)
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		result := make(map[string]any, len(e.Items))
+		for _, item := range e.Items {
+			key, ok := objectItemKey(item)
+			if !ok {
+				return Raw(exprText(expr, src))
+			}
+			result[key] = exprValue(item.ValueExpr, src)
+		}
+		return result
+	case *hclsyntax.TupleConsExpr:
+		result := make([]any, len(e.Exprs))
+		for i, elem := range e.Exprs {
+			result[i] = exprValue(elem, src)
+		}
+		return result
+	default:
+		return Raw(exprText(expr, src))
+	}
+}
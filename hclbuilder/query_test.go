@@ -0,0 +1,160 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test GetAttribute() - literals, references and nested values
+func TestGetAttribute(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "query.input.tf"))
+	require.NoError(t, err)
+
+	tests := map[string]any{
+		"resource.kong-mesh_mesh.default.type":                                              "Mesh",
+		"resource.kong-mesh_mesh.default.name":                                              hclbuilder.Raw("var.mesh_name"),
+		"resource.kong-mesh_mesh.default.skip_creating_initial_policies":                    []any{"*"},
+		"resource.kong-mesh_mesh.default.replicas":                                          int64(3),
+		"resource.kong-mesh_mesh.default.ratio":                                             0.5,
+		"resource.kong-mesh_mesh.default.enabled":                                           true,
+		"resource.kong-mesh_mesh.default.routing.zone_egress":                               false,
+		`resource.kong-mesh_mesh.default.routing.tags."kuma.io/service"`:                    "backend",
+		"resource.kong-mesh_mesh.default.lifecycle.prevent_destroy":                         true,
+		"resource.kong-mesh_mesh_traffic_permission.allow_all.mesh":                         hclbuilder.Raw("kong-mesh_mesh.default.name"),
+		"resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[0].target_ref.kind": "Mesh",
+		"resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[0].default": map[string]any{
+			"action": hclbuilder.Raw(`upper("allow")`),
+		},
+		"data.konnect_mesh_control_plane.cp.name": "cp",
+		"variable.mesh_name.default":              "default",
+		`provider."kong-mesh".server_url`:         "http://localhost:5681",
+	}
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			value, ok := builder.GetAttribute(path)
+			require.True(t, ok)
+			require.Equal(t, expected, value)
+		})
+	}
+}
+
+// Test GetAttribute() - strings are told apart from references, and empty lists from missing attributes
+func TestGetAttribute_Distinguishable(t *testing.T) {
+	builder, err := hclbuilder.FromString(`resource "kong-mesh_mesh" "default" {
+  description = "var.mesh_name"
+  name        = var.mesh_name
+  tags        = []
+  labels      = { zones = [] }
+}
+`)
+	require.NoError(t, err)
+
+	value, ok := builder.GetAttribute("resource.kong-mesh_mesh.default.description")
+	require.True(t, ok)
+	require.Equal(t, "var.mesh_name", value)
+
+	value, ok = builder.GetAttribute("resource.kong-mesh_mesh.default.name")
+	require.True(t, ok)
+	require.Equal(t, hclbuilder.Raw("var.mesh_name"), value)
+
+	value, ok = builder.GetAttribute("resource.kong-mesh_mesh.default.tags")
+	require.True(t, ok)
+	require.NotNil(t, value)
+	require.Equal(t, []any{}, value)
+
+	value, ok = builder.GetAttribute("resource.kong-mesh_mesh.default.labels")
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"zones": []any{}}, value)
+
+	// Raw values are written back as the same expression
+	name, _ := builder.GetAttribute("resource.kong-mesh_mesh.default.name")
+	builder.SetAttribute("resource.kong-mesh_mesh.copy.name", name)
+	require.NoError(t, builder.Err())
+	require.Equal(t, "var.mesh_name", builder.GetRaw("resource.kong-mesh_mesh.copy.name"))
+}
+
+// Test GetAttribute() - missing attributes and invalid paths
+func TestGetAttribute_Missing(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "query.input.tf"))
+	require.NoError(t, err)
+
+	paths := []string{
+		"resource.kong-mesh_mesh.default.missing",
+		"resource.kong-mesh_mesh.default.routing.missing",
+		"resource.kong-mesh_mesh.other.name",
+		"resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[1]",
+		"resource.kong-mesh_mesh_traffic_permission.allow_all.spec.from[*].default",
+		"resource.kong-mesh_mesh.default",
+		`resource.kong-mesh_mesh."default`,
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			value, ok := builder.GetAttribute(path)
+			require.False(t, ok)
+			require.Nil(t, value)
+			require.Empty(t, builder.GetRaw(path))
+		})
+	}
+	require.NoError(t, builder.Err())
+}
+
+// Test GetRaw()
+func TestGetRaw(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "query.input.tf"))
+	require.NoError(t, err)
+
+	require.Equal(t, `"Mesh"`, builder.GetRaw("resource.kong-mesh_mesh.default.type"))
+	require.Equal(t, "var.mesh_name", builder.GetRaw("resource.kong-mesh_mesh.default.name"))
+	require.Equal(t, `["*"]`, builder.GetRaw("resource.kong-mesh_mesh.default.skip_creating_initial_policies"))
+
+	policy := builder.Block("resource.kong-mesh_mesh_traffic_permission.allow_all")
+	require.Equal(t, `upper("allow")`, policy.GetRaw("spec.from[0].default.action"))
+	require.Equal(t, "{\n          kind = \"Mesh\"\n        }", policy.GetRaw("spec.from[0].target_ref"))
+
+	value, ok := policy.GetAttribute("spec.from[0].target_ref")
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"kind": "Mesh"}, value)
+}
+
+// Test Blocks() and Resources()
+func TestBlocks(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "query.input.tf"))
+	require.NoError(t, err)
+
+	require.Equal(t, []hclbuilder.BlockRef{
+		{
+			Type:    "resource",
+			Labels:  []string{"kong-mesh_mesh", "default"},
+			Path:    "resource.kong-mesh_mesh.default",
+			Address: "kong-mesh_mesh.default",
+		},
+		{
+			Type:    "resource",
+			Labels:  []string{"kong-mesh_mesh_traffic_permission", "allow_all"},
+			Path:    "resource.kong-mesh_mesh_traffic_permission.allow_all",
+			Address: "kong-mesh_mesh_traffic_permission.allow_all",
+		},
+	}, builder.Blocks("resource"))
+
+	require.Equal(t, []hclbuilder.BlockRef{
+		{Type: "variable", Labels: []string{"mesh_name"}, Path: "variable.mesh_name"},
+	}, builder.Blocks("variable"))
+	require.Len(t, builder.Blocks(""), 6)
+	require.Empty(t, builder.Blocks("output"))
+
+	// Paths can be used to target the blocks
+	for _, ref := range builder.Blocks("") {
+		require.True(t, builder.Block(ref.Path).Exists(), ref.Path)
+	}
+
+	require.Equal(t, []string{
+		"data.konnect_mesh_control_plane.cp",
+		"kong-mesh_mesh.default",
+		"kong-mesh_mesh_traffic_permission.allow_all",
+	}, builder.Resources())
+	require.Empty(t, hclbuilder.New().Resources())
+}
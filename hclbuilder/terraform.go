@@ -0,0 +1,148 @@
+package hclbuilder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Source returns the registry source address of the provider,
+// e.g. "kong/kong-mesh".
+func (p ProviderType) Source() string {
+	return "kong/" + string(p)
+}
+
+// Terraform returns a BlockBuilder for the terraform block, creating it at
+// the top of the configuration if it does not exist.
+// Example: builder.Terraform().SetBlock("cloud", map[string]any{"organization": "kong"})
+func (b *Builder) Terraform() *BlockBuilder {
+	b = b.mutable()
+	return &BlockBuilder{builder: b, block: b.terraformBlock(), path: "terraform"}
+}
+
+// RequiredVersion sets the Terraform version constraint of the configuration.
+// Example: builder.RequiredVersion(">= 1.5") writes terraform { required_version = ">= 1.5" }.
+func (b *Builder) RequiredVersion(constraint string) *Builder {
+	b = b.mutable()
+	b.terraformBlock().Body().SetAttributeValue("required_version", cty.StringVal(constraint))
+	return b
+}
+
+// RequiredProvider declares a provider in terraform.required_providers with
+// its source address and, if not empty, its version constraint. Other
+// settings of an existing declaration (e.g. configuration_aliases) are kept.
+// Example: builder.RequiredProvider("kong-mesh", "kong/kong-mesh", "~> 2.0") writes
+//
+//	terraform {
+//	  required_providers {
+//	    kong-mesh = {
+//	      source  = "kong/kong-mesh"
+//	      version = "~> 2.0"
+//	    }
+//	  }
+//	}
+func (b *Builder) RequiredProvider(name, source, version string) *Builder {
+	b = b.mutable()
+	path := "terraform.required_providers." + name
+	if !hclsyntax.ValidIdentifier(name) {
+		b.addError("RequiredProvider", path, fmt.Errorf("%w: %q is not a valid provider name", ErrInvalidPath, name))
+		return b
+	}
+
+	providers := findOrCreateBlock(b.terraformBlock().Body(), "required_providers", nil)
+
+	var src []byte
+	if attr := providers.Body().GetAttribute(name); attr != nil {
+		src = exprSource(attr)
+	}
+	settings := map[string]string{"source": source}
+	if version != "" {
+		settings["version"] = version
+	}
+	for _, key := range []string{"source", "version"} {
+		value, ok := settings[key]
+		if !ok {
+			continue
+		}
+		edited, err := editNested(src, []pathStep{{key: key}}, replaceWith(hclwrite.TokensForValue(cty.StringVal(value)).Bytes()))
+		if err != nil {
+			b.addError("RequiredProvider", path, err)
+			return b
+		}
+		src = edited
+	}
+
+	tokens, err := exprTokens(src)
+	if err != nil {
+		b.addError("RequiredProvider", path, err)
+		return b
+	}
+	providers.Body().SetAttributeRaw(name, tokens)
+	return b
+}
+
+// WithRequiredProvider declares one of the Kong providers in
+// terraform.required_providers, with its registry source (see RequiredProvider).
+// Example: builder.WithRequiredProvider(hclbuilder.KongMesh, "~> 2.0")
+func (b *Builder) WithRequiredProvider(provider ProviderType, version string) *Builder {
+	return b.RequiredProvider(string(provider), provider.Source(), version)
+}
+
+// RemoveRequiredProvider removes a provider from terraform.required_providers.
+// required_providers is removed when it becomes empty. If the provider is not
+// declared, this method does nothing and records an error (see Err).
+func (b *Builder) RemoveRequiredProvider(name string) *Builder {
+	b = b.mutable()
+	path := "terraform.required_providers." + name
+
+	terraform := findBlock(b.file.Body(), "terraform", nil)
+	if terraform == nil {
+		b.addError("RemoveRequiredProvider", path, ErrBlockNotFound)
+		return b
+	}
+	providers := findBlock(terraform.Body(), "required_providers", nil)
+	if providers == nil || providers.Body().RemoveAttribute(name) == nil {
+		b.addError("RemoveRequiredProvider", path, ErrAttributeNotFound)
+		return b
+	}
+	if len(providers.Body().Attributes()) == 0 && len(providers.Body().Blocks()) == 0 {
+		terraform.Body().RemoveBlock(providers)
+	}
+	return b
+}
+
+// Backend sets the backend of the configuration, replacing any existing one.
+// Example: builder.Backend("s3", map[string]any{"bucket": "state", "key": "mesh.tfstate"})
+// writes terraform { backend "s3" { bucket = "state" key = "mesh.tfstate" } }.
+func (b *Builder) Backend(backendType string, config map[string]any) *Builder {
+	b = b.mutable()
+	path := JoinPath("terraform", "backend", backendType)
+
+	block, err := newBlock("backend", NestedBlock{Labels: []string{backendType}, Attributes: config})
+	if err != nil {
+		b.addError("Backend", path, err)
+		return b
+	}
+
+	body := b.terraformBlock().Body()
+	for _, existing := range body.Blocks() {
+		if existing.Type() == "backend" {
+			body.RemoveBlock(existing)
+		}
+	}
+	body.AppendBlock(block)
+	return b
+}
+
+// terraformBlock returns the terraform block, creating it at the top of the
+// configuration if it does not exist.
+func (b *Builder) terraformBlock() *hclwrite.Block {
+	if block := findBlock(b.file.Body(), "terraform", nil); block != nil {
+		return block
+	}
+	block := hclwrite.NewBlock("terraform", nil)
+	prependBlock(b.file.Body(), block)
+	return block
+}
@@ -0,0 +1,64 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test RequiredVersion(), RequiredProvider() and Backend() - new terraform block
+func TestTerraform_New(t *testing.T) {
+	builder := hclbuilder.NewWithProvider(hclbuilder.KongMesh, "http://localhost:5681")
+	builder.RequiredVersion(">= 1.5").
+		WithRequiredProvider(hclbuilder.KongMesh, "~> 2.0").
+		RequiredProvider("konnect", "kong/konnect", "").
+		Backend("local", map[string]any{"path": "terraform.tfstate"})
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "terraform-new.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+
+	value, ok := builder.GetAttribute("terraform.required_providers.kong-mesh.source")
+	require.True(t, ok)
+	require.Equal(t, "kong/kong-mesh", value)
+}
+
+// Test RequiredProvider(), RemoveRequiredProvider() and Backend() - existing terraform block
+func TestTerraform_Existing(t *testing.T) {
+	builder, err := hclbuilder.FromFile(filepath.Join("testdata", "terraform-existing.input.tf"))
+	require.NoError(t, err)
+
+	builder.RequiredVersion(">= 1.6").
+		RequiredProvider("kong-mesh", "kong/kong-mesh", "~> 2.1").
+		RemoveRequiredProvider("konnect").
+		Backend("s3", map[string]any{"bucket": "state", "key": "mesh.tfstate"})
+	builder.Terraform().SetBlock("cloud", map[string]any{"organization": "kong"})
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "terraform-existing.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test RequiredProvider() and RemoveRequiredProvider() - errors
+func TestErr_Terraform(t *testing.T) {
+	builder := hclbuilder.New()
+	builder.RemoveRequiredProvider("kong-mesh")
+	requirePathError(t, builder.Err(), "RemoveRequiredProvider", "terraform.required_providers.kong-mesh", hclbuilder.ErrBlockNotFound)
+
+	builder = hclbuilder.New()
+	builder.RequiredProvider("kong mesh", "kong/kong-mesh", "")
+	requirePathError(t, builder.Err(), "RequiredProvider", "terraform.required_providers.kong mesh", hclbuilder.ErrInvalidPath)
+
+	builder = hclbuilder.New()
+	builder.WithRequiredProvider(hclbuilder.KongMesh, "").RemoveRequiredProvider("konnect")
+	requirePathError(t, builder.Err(), "RemoveRequiredProvider", "terraform.required_providers.konnect", hclbuilder.ErrAttributeNotFound)
+
+	// The last provider removes required_providers
+	builder.RemoveRequiredProvider("kong-mesh")
+	require.Equal(t, "terraform {\n}\n", builder.Build())
+}
@@ -28,6 +28,9 @@ func CreateMeshAndModifyFields(
 	builder *Builder,
 	mesh *Builder,
 ) resource.TestCase {
+	// Work on copies so that the caller's builders can be reused across test cases
+	builder, mesh = builder.Clone(), mesh.Clone()
+
 	meshResourcePath := mesh.ResourcePath()
 	return resource.TestCase{
 		ProtoV6ProviderFactories: providerFactory,
@@ -94,6 +97,9 @@ func CreatePolicyAndModifyFields(
 	mesh *Builder,
 	policy *Builder,
 ) resource.TestCase {
+	// Work on copies so that the caller's builders can be reused across test cases
+	builder, mesh, policy = builder.Clone(), mesh.Clone(), policy.Clone()
+
 	policyResourcePath := policy.ResourcePath()
 	meshResourcePath := mesh.ResourcePath()
 
@@ -156,6 +162,9 @@ func NotImportedResourceShouldError(
 	policy *Builder,
 	preConfigFn func(),
 ) resource.TestCase {
+	// Work on copies so that the caller's builders can be reused across test cases
+	builder, mesh, policy = builder.Clone(), mesh.Clone(), policy.Clone()
+
 	expectedErr := regexp.MustCompile(`MeshTrafficPermission already exists`)
 
 	policyResourcePath := policy.ResourcePath()
@@ -205,6 +214,9 @@ func ShouldBeAbleToStoreSecrets(
 	scert *Builder,
 	skey *Builder,
 ) resource.TestCase {
+	// Work on copies so that the caller's builders can be reused across test cases
+	builder, mesh, scert, skey = builder.Clone(), mesh.Clone(), scert.Clone(), skey.Clone()
+
 	meshResourcePath := mesh.ResourcePath()
 	scertResourcePath := scert.ResourcePath()
 	skeyResourcePath := skey.ResourcePath()
@@ -0,0 +1,37 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+  spec = {
+    target_ref = {
+      kind        = "Mesh"
+      proxy_types = ["Gateway", "Sidecar"]
+    }
+    from = [
+      {
+        target_ref = {
+          kind = "MeshSubset"
+        }
+      },
+      {
+        target_ref = {
+          kind        = "Mesh"
+          proxy_types = ["Sidecar"]
+        }
+        default = {
+          action = "Deny"
+        }
+      },
+      {
+        # Traffic from the backend
+        target_ref = {
+          kind = "MeshService"
+          name = "frontend"
+        }
+        default = {
+          action = "Deny"
+        }
+      },
+    ]
+  }
+}
@@ -0,0 +1,30 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+  spec = {
+    # Applies to every proxy in the mesh
+    target_ref = {
+      kind        = "Mesh"
+      name        = kong-mesh_mesh.default.name # same as the mesh
+      proxy_types = ["Sidecar"]
+    }
+    from = [{
+      target_ref = {
+        kind = var.kind
+      }
+      default = {
+        action = var.allow ? "Allow" : "Deny"
+      }
+    }]
+    description = <<-EOT
+      Allow all traffic
+    EOT
+    labels      = merge(local.labels, { team = "kong" })
+    rules = {
+      default = {
+        action = var.action
+      }
+    }
+  }
+}
@@ -0,0 +1,12 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = "default"
+  spec = {
+    target_ref = { kind = "Mesh", name = "default" }
+    labels     = { team = "mesh", zone = "eu", }
+    tags = {
+      env = "prod"
+    }
+  }
+}
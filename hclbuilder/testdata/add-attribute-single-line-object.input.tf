@@ -0,0 +1,10 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = "default"
+  spec = {
+    target_ref = { kind = "Mesh" }
+    labels     = { team = "mesh", }
+    tags       = {}
+  }
+}
@@ -4,7 +4,7 @@ provider "kong-mesh" {
 resource "kong-mesh_mesh_traffic_permission" "allow_all" {
   mesh     = "kong-mesh_mesh.default.name"
   name     = "allow-all"
-  provider = "kong-mesh"
+  provider = kong-mesh
   spec = {
     from = [{
       target_ref = {
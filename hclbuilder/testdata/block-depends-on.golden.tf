@@ -0,0 +1,20 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+
+resource "kong-mesh_mesh" "default" {
+  type                           = "Mesh"
+  name                           = "default"
+  skip_creating_initial_policies = ["*"]
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type       = "MeshTrafficPermission"
+  name       = "allow-all"
+  mesh       = "default"
+  depends_on = [kong-mesh_mesh.default]
+}
+
+variable "mesh_name" {
+  default = "default"
+}
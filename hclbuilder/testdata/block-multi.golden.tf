@@ -0,0 +1,22 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+  routing = {
+    default_forbid_mesh_external_service_access = true
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+}
+
+variable "mesh_name" {
+  default     = "default"
+  description = "Name of the mesh"
+}
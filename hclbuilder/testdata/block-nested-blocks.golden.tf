@@ -0,0 +1,29 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+
+resource "kong-mesh_mesh" "default" {
+  type                           = "Mesh"
+  name                           = "default"
+  skip_creating_initial_policies = ["*"]
+  lifecycle {
+    create_before_destroy = true
+    prevent_destroy       = true
+  }
+  provisioner "local-exec" {
+    command = "echo 1"
+  }
+  provisioner "local-exec" {
+    command = "echo 2"
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = "default"
+}
+
+variable "mesh_name" {
+  default = "default"
+}
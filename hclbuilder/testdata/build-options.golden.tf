@@ -0,0 +1,29 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  for_each = toset(["a", "b"])
+  provider = kong-mesh.eu
+
+  description = "Allow all traffic"
+  labels = {
+    zone = "eu"
+    app  = "demo"
+  }
+  mesh = kong-mesh_mesh.default.name
+  # Name of the policy
+  name = "allow-all"
+  spec = {
+    from = [{
+      target_ref = { kind = "Mesh" }
+    }]
+  }
+  type = "MeshTrafficPermission"
+
+  depends_on = [kong-mesh_mesh.default]
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+provider "kong-mesh" {
+  alias      = "eu"
+  server_url = "http://localhost:5681"
+}
@@ -0,0 +1,29 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  depends_on = [kong-mesh_mesh.default]
+  lifecycle {
+    prevent_destroy = true
+  }
+  # Name of the policy
+  name = "allow-all"
+
+  spec = {
+    from = [{
+      target_ref = { kind = "Mesh" }
+    }]
+  }
+  provider = kong-mesh.eu
+  for_each = toset(["a", "b"])
+  mesh = kong-mesh_mesh.default.name
+  labels = {
+    zone = "eu"
+    app  = "demo"
+  }
+}
+
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+  alias  = "eu"
+}
+
+
@@ -0,0 +1,7 @@
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+  routing = {
+    zone_egress = true
+  }
+}
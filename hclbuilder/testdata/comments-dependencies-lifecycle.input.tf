@@ -0,0 +1,28 @@
+# Policies of the zone
+
+# Allows all traffic
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+
+  # Ordering
+  depends_on = [
+    # why dep: the mesh must exist first
+    kong-mesh_mesh.default,
+    module.networking, # inline
+  ]
+
+  # Never recreated automatically
+  lifecycle {
+    # Rolled out in place
+    create_before_destroy = true # zero downtime
+    ignore_changes        = [labels] # set by the operator
+
+    # Checked on every plan
+    precondition {
+      condition     = var.mesh_name != ""
+      error_message = "The mesh name must not be empty."
+    }
+  }
+}
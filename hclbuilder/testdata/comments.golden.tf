@@ -0,0 +1,29 @@
+# Example configuration for a zone
+
+# Mesh shared by the policies below
+resource "kong-mesh_mesh" "default" {
+  for_each = toset(["eu", "us"])
+
+  name = "default"
+  # Allow everything until policies are set up
+  routing = {
+    default_forbid_mesh_external_service_access = true # open
+  }
+  # Resource type
+  type = "Mesh" # must be Mesh
+
+  # Protects the mesh
+  lifecycle {
+    create_before_destroy = true
+    prevent_destroy       = true
+  }
+}
+
+# Generated policy
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  count = 1
+
+  mesh = kong-mesh_mesh.default.name
+  name = "allow-all"
+  type = "MeshTrafficPermission"
+}
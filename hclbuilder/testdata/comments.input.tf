@@ -0,0 +1,25 @@
+# Example configuration for a zone
+
+# Mesh shared by the policies below
+resource "kong-mesh_mesh" "default" {
+  # Resource type
+  type = "Mesh" # must be Mesh
+  name = "default"
+
+  # Allow everything until policies are set up
+  routing = {
+    default_forbid_mesh_external_service_access = false # open
+  }
+
+  # Protects the mesh
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+# Generated policy
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission" # kind of policy
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+}
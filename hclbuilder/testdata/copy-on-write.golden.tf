@@ -0,0 +1,7 @@
+resource "kong-mesh_mesh" "default" {
+  name = "mesh-2"
+  routing = {
+    zone_egress = true
+  }
+  description = "Default mesh"
+}
@@ -0,0 +1,22 @@
+provider "konnect" {
+  alias = "eu"
+}
+data "konnect_mesh_control_plane" "existing" {
+  id       = var.cp_id
+  provider = konnect.eu
+}
+data "konnect_mesh" "default" {
+  name = "default"
+}
+data "konnect_mesh_traffic_permission" "allow_all" {
+  mesh = data.konnect_mesh.default.name
+  name = "allow-all"
+}
+resource "konnect_mesh" "default" {
+  cp_id      = data.konnect_mesh_control_plane.existing.id
+  name       = "mesh-2"
+  depends_on = [data.konnect_mesh_control_plane.existing, data.konnect_mesh_traffic_permission.allow_all]
+}
+output "mesh_name" {
+  value = data.konnect_mesh.default.name
+}
@@ -0,0 +1,11 @@
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+  # Created by the platform team
+  depends_on = [
+    # why dep: the control plane must exist first
+    konnect_mesh_control_plane.cp,
+    module.networking, # inline
+    data.konnect_mesh_control_plane.existing,
+  ] # dependencies
+}
@@ -0,0 +1,12 @@
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+  # Created by the platform team
+  depends_on = [
+    # why dep: the control plane must exist first
+    konnect_mesh_control_plane.cp,
+    module.networking, # inline
+    # Removed below
+    "konnect_mesh_control_plane.legacy",
+  ] # dependencies
+}
@@ -0,0 +1,10 @@
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+  depends_on = [
+    konnect_mesh.m["a"],
+    module.networking,
+    data.konnect_mesh_control_plane.existing,
+    konnect_mesh.m[1],
+  ]
+}
@@ -1,5 +1,5 @@
 resource "kong-mesh_mesh" "default" {
   type       = "Mesh"
   name       = "default"
-  depends_on = [konnect_mesh_control_plane.my_meshcontrolplane]
+  depends_on = [konnect_mesh_control_plane.other_cp, konnect_mesh_control_plane.my_meshcontrolplane]
 }
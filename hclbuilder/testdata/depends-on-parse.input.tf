@@ -0,0 +1,12 @@
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+  depends_on = [
+    konnect_mesh_control_plane.cp,
+    konnect_mesh.m[0],
+    konnect_mesh.m["a"],
+    module.networking,
+    data.konnect_mesh_control_plane.existing,
+    "konnect_mesh_control_plane.legacy",
+  ]
+}
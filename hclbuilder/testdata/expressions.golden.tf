@@ -0,0 +1,27 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  labels = merge(local.labels, {
+    team = "mesh"
+  })
+  mesh = kong-mesh_mesh.default.name
+  name = "${var.prefix}-allow-all"
+  spec = {
+    from = [{
+      default = {
+        action = var.allow ? "Allow" : "Deny"
+      }
+      target_ref = {
+        kind = "Mesh"
+      }
+    }]
+    target_ref = {
+      kind = "Mesh"
+      name = kong-mesh_mesh.default.name
+    }
+    rules = jsondecode(var.rules)
+  }
+  type        = "MeshTrafficPermission"
+  description = "[]"
+}
+variable "allow" {
+  default = "true"
+}
@@ -9,10 +9,6 @@ resource "kong-mesh_mesh_traffic_permission" "existing" {
   name = "allow-all"
   mesh = "kong-mesh_mesh.default.name"
 }
-resource "kong-mesh_mesh" {
-}
-resource "kong-mesh_mesh_traffic_permission" {
-}
 resource "kong-mesh_mesh_traffic_permission" "new_policy" {
   name = "new-policy"
 }
@@ -0,0 +1,28 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+}
+import {
+  to = kong-mesh_mesh.default
+  id = "{\"name\":\"renamed\"}"
+}
+import {
+  to = konnect_mesh.m["a"]
+  id = jsonencode({
+    cp_id = var.cp_id
+    name  = "a"
+  })
+}
+moved {
+  from = kong-mesh_mesh.old
+  to   = kong-mesh_mesh.default
+}
+removed {
+  from = kong-mesh_mesh_traffic_permission.allow_all
+  lifecycle {
+    destroy = true
+  }
+}
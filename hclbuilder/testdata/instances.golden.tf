@@ -0,0 +1,24 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+resource "kong-mesh_mesh" "default" {
+  for_each = toset(["a", "b"])
+
+  name = "mesh-${each.key}"
+  type = "Mesh"
+}
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  count = 2
+
+  mesh       = kong-mesh_mesh.default["a"].name
+  name       = format("allow-all-%d", count.index)
+  provider   = kong-mesh
+  type       = "MeshTrafficPermission"
+  depends_on = [kong-mesh_mesh.default["a"]]
+}
+resource "kong-mesh_mesh_traffic_permission" "per_mesh" {
+  for_each = kong-mesh_mesh.default
+
+  mesh = kong-mesh_mesh.default[each.key].name
+  name = each.value
+}
@@ -0,0 +1,26 @@
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = "default"
+
+  lifecycle {
+    prevent_destroy       = true
+    ignore_changes        = all
+    create_before_destroy = true
+
+    precondition {
+      condition     = var.mesh_name != ""
+      error_message = "The mesh name must not be empty."
+    }
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+  lifecycle {
+    ignore_changes       = [labels, spec.from[0].default, labels["kuma.io/zone"]]
+    prevent_destroy      = true
+    replace_triggered_by = [kong-mesh_mesh.default]
+  }
+}
@@ -0,0 +1,14 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5682"
+}
+
+resource "kong-mesh_mesh" "default" {
+  type                           = "Mesh"
+  name                           = var.mesh_name
+  skip_creating_initial_policies = ["*"]
+}
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+}
@@ -0,0 +1,6 @@
+output "mesh_name" {
+  value = kong-mesh_mesh.default.name
+}
+output "policy_name" {
+  value = kong-mesh_mesh_traffic_permission.allow_all.name
+}
@@ -0,0 +1,3 @@
+provider "konnect" {
+  server_url = "https://global.api.konghq.com"
+}
@@ -0,0 +1,8 @@
+variable "mesh_name" {
+  type        = string
+  default     = "default"
+  description = "Name of the mesh"
+}
+variable "zone" {
+  default = "eu"
+}
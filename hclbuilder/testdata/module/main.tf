@@ -0,0 +1,8 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+
+resource "kong-mesh_mesh" "default" {
+  type = "Mesh"
+  name = var.mesh_name
+}
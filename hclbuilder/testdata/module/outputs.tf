@@ -0,0 +1,3 @@
+output "mesh_name" {
+  value = kong-mesh_mesh.default.name
+}
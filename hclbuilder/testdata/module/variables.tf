@@ -0,0 +1,4 @@
+variable "mesh_name" {
+  type    = string
+  default = "default"
+}
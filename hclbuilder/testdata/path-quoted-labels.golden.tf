@@ -0,0 +1,7 @@
+provider "kong-mesh.eu" {
+  kong_mesh_server_url = "https://eu.example.com"
+}
+resource "konnect_mesh" "a.b" {
+  name        = "a.b"
+  description = "first"
+}
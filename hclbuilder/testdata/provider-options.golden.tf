@@ -0,0 +1,32 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5682"
+}
+provider "kong-mesh" {
+  alias = "eu"
+  http_headers = {
+    X-Zone = "eu"
+  }
+  server_url = "http://eu.example.com:5681"
+}
+resource "kong-mesh_mesh_traffic_permission" "allow_all_eu" {
+  mesh     = "kong-mesh_mesh.default.name"
+  name     = "allow-all"
+  provider = kong-mesh.eu
+  type     = "MeshTrafficPermission"
+}
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  mesh     = "kong-mesh_mesh.default.name"
+  name     = "allow-all"
+  provider = kong-mesh
+  type     = "MeshTrafficPermission"
+}
+provider "konnect" {
+  alias                 = "us"
+  personal_access_token = var.konnect_token
+  server_url            = "https://us.api.konghq.com"
+}
+resource "konnect_mesh_control_plane" "cp1" {
+  description = "Test control plane"
+  name        = "my-control-plane"
+  provider    = konnect.us
+}
@@ -0,0 +1,53 @@
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+
+data "konnect_mesh_control_plane" "cp" {
+  name = "cp"
+}
+
+resource "kong-mesh_mesh" "default" {
+  type                           = "Mesh"
+  name                           = var.mesh_name
+  skip_creating_initial_policies = ["*"]
+  replicas                       = 3
+  ratio                          = 0.5
+  enabled                        = true
+
+  routing = {
+    zone_egress = false
+    tags = {
+      "kuma.io/service" = "backend"
+    }
+  }
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+  spec = {
+    from = [
+      {
+        target_ref = {
+          kind = "Mesh"
+        }
+        default = {
+          action = upper("allow")
+        }
+      },
+    ]
+  }
+}
+
+module "networking" {
+  source = "./networking"
+}
+
+variable "mesh_name" {
+  default = "default"
+}
@@ -3,12 +3,12 @@ resource "kong-mesh_mesh" "default" {
   type = "Mesh"
   config = {
     networking = {
-      advanced = {
-        retries = 3
-      }
       basic = {
         enabled = true
       }
+      advanced = {
+        retries = 3
+      }
     }
     security = {
       enabled = true
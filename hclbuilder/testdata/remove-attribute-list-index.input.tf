@@ -0,0 +1,31 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+  spec = {
+    target_ref = {
+      kind        = "Mesh"
+      proxy_types = ["Sidecar"]
+    }
+    from = [
+      {
+        target_ref = {
+          kind = "Mesh"
+        }
+        default = {
+          action = "Allow"
+        }
+      },
+      {
+        # Traffic from the backend
+        target_ref = {
+          kind = "MeshService"
+          name = "backend"
+        }
+        default = {
+          action = var.backend_action
+        }
+      },
+    ]
+  }
+}
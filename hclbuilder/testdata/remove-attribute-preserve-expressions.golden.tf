@@ -0,0 +1,20 @@
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  type = "MeshTrafficPermission"
+  name = "allow-all"
+  mesh = kong-mesh_mesh.default.name
+  spec = {
+    # Applies to every proxy in the mesh
+    target_ref = {
+      kind = "Mesh"
+    }
+    from = [{
+      target_ref = {
+        kind = var.kind
+      }
+      default = {
+        action = var.allow ? "Allow" : "Deny"
+      }
+    }]
+    labels = merge(local.labels, { team = "mesh" })
+  }
+}
@@ -0,0 +1,22 @@
+data "konnect_mesh_control_plane" "existing" {
+  filter = "name eq 'cp'"
+}
+
+provider "konnect" {
+  alias      = "eu"
+  server_url = "https://eu.api.konghq.com"
+}
+
+variable "mesh_name" {
+  default = "m1"
+}
+
+output "mesh_id" {
+  value = konnect_mesh.m.id
+}
+
+module "networking" {
+  source = "./networking"
+}
+
+mesh_count = 1
@@ -0,0 +1,15 @@
+terraform {
+  required_version = ">= 1.5"
+}
+locals {
+  mesh_name = "default"
+}
+variable "mesh_name" {
+  type = string
+}
+resource "konnect_mesh" "m" {
+  name = "m"
+  lifecycle {
+    prevent_destroy = true
+  }
+}
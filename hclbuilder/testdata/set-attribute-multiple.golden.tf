@@ -1,4 +1,7 @@
-resource "kong-mesh_mesh" {
+resource "kong-mesh_mesh" "default" {
+  name = "mesh-1"
+  type = "Mesh"
 }
-resource "kong-mesh_mesh_traffic_permission" {
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  mesh = "kong-mesh_mesh.default.name"
 }
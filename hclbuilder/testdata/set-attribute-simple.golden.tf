@@ -1,2 +1,3 @@
-resource "kong-mesh_mesh" {
+resource "kong-mesh_mesh" "default" {
+  name = "mesh-1"
 }
@@ -0,0 +1,38 @@
+resource "test" "values" {
+  empty_ints    = []
+  float32_value = 0.1
+  int32_value   = 32
+  int8_value    = -8
+  labels = {
+    team = "mesh"
+  }
+  nil_pointer = null
+  nil_value   = null
+  pointer     = "backend"
+  rules = [{
+    target_ref = {
+      kind = "Mesh"
+    }
+    }, {
+    target_ref = {
+      kind        = "MeshService"
+      proxy_types = ["Sidecar"]
+    }
+  }]
+  struct_value = {
+    kind        = "MeshService"
+    name        = "backend"
+    proxy_types = []
+  }
+  structs = [{
+    kind        = "Mesh"
+    name        = null
+    proxy_types = ["Gateway"]
+    }, {
+    kind        = "MeshService"
+    name        = "backend"
+    proxy_types = []
+  }]
+  uint64_value = 18446744073709551615
+  uint_value   = 7
+}
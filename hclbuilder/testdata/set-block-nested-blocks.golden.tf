@@ -0,0 +1,22 @@
+resource "kong-mesh_mesh" "default" {
+  name = "mesh-1"
+  type = "Mesh"
+  dynamic "backend" {
+    for_each = ["builtin"]
+    content {
+      type = "builtin"
+    }
+  }
+  lifecycle {
+    prevent_destroy = true
+  }
+  provisioner "local-exec" {
+    command = "echo created"
+  }
+  provisioner "local-exec" {
+    command = "echo destroyed"
+    when    = "destroy"
+  }
+  timeouts {
+  }
+}
@@ -0,0 +1,24 @@
+# Versions
+terraform {
+  required_version = ">= 1.6"
+
+  required_providers {
+    kong-mesh = {
+      source                = "kong/kong-mesh"
+      version               = "~> 2.1"
+      configuration_aliases = [kong-mesh.eu]
+    }
+  }
+
+  backend "s3" {
+    bucket = "state"
+    key    = "mesh.tfstate"
+  }
+  cloud {
+    organization = "kong"
+  }
+}
+
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
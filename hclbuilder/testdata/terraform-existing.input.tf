@@ -0,0 +1,23 @@
+# Versions
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    kong-mesh = {
+      source                = "kong/kong-mesh"
+      version               = "~> 2.0"
+      configuration_aliases = [kong-mesh.eu]
+    }
+    konnect = {
+      source = "kong/konnect"
+    }
+  }
+
+  backend "local" {
+    path = "terraform.tfstate"
+  }
+}
+
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
@@ -0,0 +1,68 @@
+# Generated example configuration
+
+terraform {
+  required_version = ">= 1.5"
+  required_providers {
+    kong-mesh = {
+      source = "kong/kong-mesh"
+    }
+  }
+}
+provider "kong-mesh" {
+  server_url = "http://localhost:5681"
+}
+provider "kong-mesh" {
+  alias      = "eu"
+  server_url = "http://eu.example.com:5681"
+}
+variable "mesh_name" {
+  type    = string
+  default = "default"
+  validation {
+    condition     = length(var.mesh_name) > 0
+    error_message = "The mesh name must not be empty."
+  }
+}
+# Mesh shared by every zone
+resource "kong-mesh_mesh" "default" {
+  provider                       = kong-mesh.eu
+  type                           = "Mesh"
+  name                           = var.mesh_name
+  skip_creating_initial_policies = ["*"]
+  routing = {
+    default_forbid_mesh_external_service_access = false
+    zone_egress                                 = true
+  }
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = [labels]
+  }
+}
+resource "kong-mesh_mesh_traffic_permission" "allow_all" {
+  for_each = toset(["eu", "us"])
+  type     = "MeshTrafficPermission"
+  name     = "allow-all-${each.key}"
+  mesh     = kong-mesh_mesh.default.name
+  labels = {
+    "kuma.io/zone" = each.value
+    literal        = "$${not_a_reference}"
+  }
+  spec = {
+    from = [{
+      target_ref = {
+        kind = "Mesh"
+      }
+      default = {
+        action = "Allow"
+      }
+    }]
+  }
+  depends_on = [kong-mesh_mesh.default]
+}
+output "mesh_name" {
+  value = kong-mesh_mesh.default.name
+}
+import {
+  to = kong-mesh_mesh.default
+  id = "{\"name\":\"default\"}"
+}
@@ -0,0 +1,24 @@
+provider "konnect" {
+  server_url = "https://global.api.konghq.com"
+}
+
+# The mesh
+resource "konnect_mesh" "m" {
+  name = "m1"
+  spec = {
+    # Keep mTLS settings
+    mtls = {
+      enabled = true
+    }
+    routing = {
+      locality_aware = false
+    }
+  }
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+resource "konnect_mesh_control_plane" "cp" {
+  name = "cp"
+}
@@ -0,0 +1,16 @@
+resource "konnect_mesh" "m" {
+  name = "m2"
+  spec = {
+    routing = {
+      default_forbid_mesh_external_service_access = true
+    }
+  }
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+provider "konnect" {
+  alias      = "eu"
+  server_url = "https://eu.api.konghq.com"
+}
@@ -0,0 +1,24 @@
+provider "konnect" {
+  server_url = "https://global.api.konghq.com"
+}
+
+# The mesh
+resource "konnect_mesh" "m" {
+  name = "m2"
+  spec = {
+    routing = {
+      default_forbid_mesh_external_service_access = true
+    }
+  }
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+resource "konnect_mesh_control_plane" "cp" {
+  name = "cp"
+}
+provider "konnect" {
+  alias      = "eu"
+  server_url = "https://eu.api.konghq.com"
+}
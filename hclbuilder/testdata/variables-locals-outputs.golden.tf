@@ -0,0 +1,30 @@
+variable "mesh_name" {
+  type    = string
+  default = "default"
+  validation {
+    condition     = length(var.mesh_name) > 0
+    error_message = "The mesh name must not be empty."
+  }
+}
+variable "zones" {
+  type    = set(string)
+  default = ["eu"]
+}
+variable "mtls" {
+  type = object({ backends = list(string), enabled = bool })
+}
+variable "konnect_token" {
+  type      = string
+  sensitive = true
+}
+locals {
+  mesh_name  = lower(var.mesh_name)
+  zone_count = 2
+}
+resource "konnect_mesh_control_plane" "cp1" {
+  name = local.mesh_name
+}
+output "control_plane_id" {
+  value       = konnect_mesh_control_plane.cp1.id
+  description = "ID of the control plane"
+}
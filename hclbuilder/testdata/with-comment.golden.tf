@@ -0,0 +1,15 @@
+# Mesh shared by every zone.
+# Created before the policies.
+resource "kong-mesh_mesh" "default" {
+  # Name of the mesh
+  name = "default"
+  type = "Mesh"
+  # Deleting the mesh deletes its policies
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+# Name of the zone
+variable "zone" {
+  type = string
+}
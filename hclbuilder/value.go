@@ -0,0 +1,171 @@
+package hclbuilder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+var ctyValueType = reflect.TypeOf(cty.Value{})
+
+// convertToCtyValue converts a Go value to a cty.Value.
+//
+// Strings, booleans and every numeric kind map to their cty counterparts
+// (except NaN and infinite floats, which are rejected), and
+// nil (or a nil pointer) to null. Slices and arrays become lists when all
+// their elements have the same type and tuples otherwise, so that objects of
+// different shapes can share a list. Maps with string keys become objects,
+// and structs become objects made of their fields with a `cty` tag. A
+// cty.Value is used as-is.
+func convertToCtyValue(value any) (cty.Value, error) {
+	if value == nil {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+	return reflectToCtyValue(reflect.ValueOf(value))
+}
+
+func reflectToCtyValue(rv reflect.Value) (cty.Value, error) {
+	if rv.Type() == ctyValueType {
+		return rv.Interface().(cty.Value), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return cty.NullVal(cty.DynamicPseudoType), nil
+		}
+		return reflectToCtyValue(rv.Elem())
+	case reflect.String:
+		return cty.StringVal(rv.String()), nil
+	case reflect.Bool:
+		return cty.BoolVal(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cty.NumberIntVal(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cty.NumberUIntVal(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			// HCL has no literal for NaN or infinities
+			return cty.NilVal, fmt.Errorf("%w: %s %v", ErrUnsupportedValue, rv.Type(), f)
+		}
+		if rv.Kind() == reflect.Float32 {
+			// Format with 32 bits precision so that e.g. 0.1 is not written as 0.100000001490116
+			return cty.ParseNumberVal(strconv.FormatFloat(f, 'g', -1, 32))
+		}
+		return cty.NumberFloatVal(f), nil
+	case reflect.Slice, reflect.Array:
+		return sliceToCtyValue(rv)
+	case reflect.Map:
+		return mapToCtyValue(rv)
+	case reflect.Struct:
+		return structToCtyValue(rv)
+	default:
+		return cty.NilVal, fmt.Errorf("%w: %s", ErrUnsupportedValue, rv.Type())
+	}
+}
+
+func sliceToCtyValue(rv reflect.Value) (cty.Value, error) {
+	if rv.Len() == 0 {
+		// Keep the element type when it is known, e.g. []string
+		if elemType, ok := impliedElementType(rv.Type().Elem()); ok {
+			return cty.ListValEmpty(elemType), nil
+		}
+		return cty.EmptyTupleVal, nil
+	}
+
+	vals := make([]cty.Value, rv.Len())
+	sameType := true
+	for i := range vals {
+		val, err := reflectToCtyValue(rv.Index(i))
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("index %d: %w", i, err)
+		}
+		vals[i] = val
+		sameType = sameType && val.Type().Equals(vals[0].Type()) && val.Type() != cty.DynamicPseudoType
+	}
+
+	if sameType {
+		return cty.ListVal(vals), nil
+	}
+	return cty.TupleVal(vals), nil
+}
+
+func mapToCtyValue(rv reflect.Value) (cty.Value, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return cty.NilVal, fmt.Errorf("%w: %s (map keys must be strings)", ErrUnsupportedValue, rv.Type())
+	}
+	if rv.IsNil() {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+
+	vals := make(map[string]cty.Value, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		val, err := reflectToCtyValue(iter.Value())
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("key %q: %w", key, err)
+		}
+		vals[key] = val
+	}
+	return cty.ObjectVal(vals), nil
+}
+
+func structToCtyValue(rv reflect.Value) (cty.Value, error) {
+	vals := make(map[string]cty.Value)
+	hasTags := false
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		name, ok := ctyFieldName(field)
+		if !ok {
+			continue
+		}
+		hasTags = true
+
+		val, err := reflectToCtyValue(rv.Field(i))
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		vals[name] = val
+	}
+
+	if !hasTags {
+		return cty.NilVal, fmt.Errorf("%w: %s (struct fields need `cty` tags)", ErrUnsupportedValue, rv.Type())
+	}
+	return cty.ObjectVal(vals), nil
+}
+
+// ctyFieldName returns the attribute name of an exported struct field from its `cty` tag.
+func ctyFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag, ok := field.Tag.Lookup("cty")
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, name != "" && name != "-"
+}
+
+// impliedElementType returns the cty type of a list element of Go type t, if
+// it can be known without a value.
+func impliedElementType(t reflect.Type) (cty.Type, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return cty.String, true
+	case reflect.Bool:
+		return cty.Bool, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return cty.Number, true
+	default:
+		return cty.NilType, false
+	}
+}
@@ -0,0 +1,170 @@
+package hclbuilder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AddVariable adds an input variable, or replaces the one with the same name
+// in place. varType is the type constraint, given as a type expression (e.g.
+// "list(string)" or "object({ name = string })") or as a cty.Type, and can be
+// nil to accept any type. A nil defaultValue makes the variable required.
+// Example: builder.AddVariable("mesh_name", "string", "default", false) writes
+//
+//	variable "mesh_name" {
+//	  type    = string
+//	  default = "default"
+//	}
+//
+// Use Block("variable.mesh_name") to set other arguments such as description.
+func (b *Builder) AddVariable(name string, varType any, defaultValue any, sensitive bool) *Builder {
+	b = b.mutable()
+	path := JoinPath("variable", name)
+	if !hclsyntax.ValidIdentifier(name) {
+		b.addError("AddVariable", path, fmt.Errorf("%w: %q is not a valid variable name", ErrInvalidPath, name))
+		return b
+	}
+
+	block := hclwrite.NewBlock("variable", []string{name})
+	body := block.Body()
+	if varType != nil {
+		tokens, err := typeTokens(varType)
+		if err != nil {
+			b.addError("AddVariable", path, err)
+			return b
+		}
+		body.SetAttributeRaw("type", tokens)
+	}
+	if defaultValue != nil {
+		tokens, err := valueTokens(defaultValue)
+		if err != nil {
+			b.addError("AddVariable", path, err)
+			return b
+		}
+		body.SetAttributeRaw("default", tokens)
+	}
+	if sensitive {
+		body.SetAttributeValue("sensitive", cty.True)
+	}
+
+	upsertBlock(b.file.Body(), block, MergeReplace, nil)
+	return b
+}
+
+// AddVariableValidation adds a validation block to an existing variable.
+// condition is an HCL expression, written as-is.
+// Example: builder.AddVariableValidation("mesh_name", "length(var.mesh_name) > 0", "The mesh name must not be empty.")
+//
+// If the variable does not exist, this method does nothing and records an
+// error (see Err).
+func (b *Builder) AddVariableValidation(name, condition, errorMessage string) *Builder {
+	b = b.mutable()
+	path := JoinPath("variable", name)
+	variable := findBlock(b.file.Body(), "variable", []string{name})
+	if variable == nil {
+		b.addError("AddVariableValidation", path, ErrBlockNotFound)
+		return b
+	}
+
+	tokens, err := Raw(condition).tokens()
+	if err != nil {
+		b.addError("AddVariableValidation", path, err)
+		return b
+	}
+	validation := variable.Body().AppendNewBlock("validation", nil)
+	validation.Body().SetAttributeRaw("condition", tokens)
+	validation.Body().SetAttributeValue("error_message", cty.StringVal(errorMessage))
+	return b
+}
+
+// SetLocal sets a local value, in the locals block defining it or else in the
+// first locals block, which is created if needed. Strings are written as
+// quoted strings; use Ref, Raw or Func for expressions.
+// Example: builder.SetLocal("mesh_name", hclbuilder.Func("lower", hclbuilder.Ref("var.mesh_name")))
+func (b *Builder) SetLocal(name string, value any) *Builder {
+	b = b.mutable()
+	path := JoinPath("locals", name)
+	if !hclsyntax.ValidIdentifier(name) {
+		b.addError("SetLocal", path, fmt.Errorf("%w: %q is not a valid local value name", ErrInvalidPath, name))
+		return b
+	}
+	tokens, err := valueTokens(value)
+	if err != nil {
+		b.addError("SetLocal", path, err)
+		return b
+	}
+
+	var locals *hclwrite.Block
+	for _, block := range b.file.Body().Blocks() {
+		if block.Type() != "locals" {
+			continue
+		}
+		if locals == nil {
+			locals = block
+		}
+		if block.Body().GetAttribute(name) != nil {
+			locals = block
+			break
+		}
+	}
+	if locals == nil {
+		locals = b.file.Body().AppendNewBlock("locals", nil)
+	}
+	locals.Body().SetAttributeRaw(name, tokens)
+	return b
+}
+
+// AddOutput adds an output value, or replaces the one with the same name in
+// place. Strings are written as quoted strings; use Ref, Raw or Func for
+// expressions.
+// Example: builder.AddOutput("control_plane_id", cp.Ref("id")) writes
+//
+//	output "control_plane_id" {
+//	  value = konnect_mesh_control_plane.cp1.id
+//	}
+func (b *Builder) AddOutput(name string, value any) *Builder {
+	b = b.mutable()
+	path := JoinPath("output", name)
+	if !hclsyntax.ValidIdentifier(name) {
+		b.addError("AddOutput", path, fmt.Errorf("%w: %q is not a valid output name", ErrInvalidPath, name))
+		return b
+	}
+	tokens, err := valueTokens(value)
+	if err != nil {
+		b.addError("AddOutput", path, err)
+		return b
+	}
+
+	block := hclwrite.NewBlock("output", []string{name})
+	block.Body().SetAttributeRaw("value", tokens)
+	upsertBlock(b.file.Body(), block, MergeReplace, nil)
+	return b
+}
+
+// typeTokens returns the tokens of a type constraint given as a type
+// expression or a cty.Type. Type expressions are checked, so that e.g.
+// "list(strin)" records ErrInvalidExpression.
+func typeTokens(varType any) (hclwrite.Tokens, error) {
+	var src []byte
+	switch varType := varType.(type) {
+	case string:
+		src = []byte(varType)
+	case cty.Type:
+		src = hclwrite.Format([]byte(typeexpr.TypeString(varType)))
+	default:
+		return nil, fmt.Errorf("%w: type constraint of type %T", ErrUnsupportedValue, varType)
+	}
+
+	expr, err := parseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExpression, err)
+	}
+	if _, _, diags := typeexpr.TypeConstraintWithDefaults(expr); diags.HasErrors() {
+		return nil, fmt.Errorf("%w: %q is not a type constraint: %s", ErrInvalidExpression, src, diags.Error())
+	}
+	return exprTokens(src)
+}
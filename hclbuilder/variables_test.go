@@ -0,0 +1,91 @@
+package hclbuilder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/Kong/shared-speakeasy/hclbuilder"
+)
+
+// Test AddVariable(), SetLocal() and AddOutput()
+func TestVariablesLocalsOutputs(t *testing.T) {
+	builder := hclbuilder.New().
+		AddVariable("mesh_name", "string", "default", false).
+		AddVariable("zones", "list(string)", []string{"eu", "us"}, false).
+		AddVariable("mtls", cty.Object(map[string]cty.Type{
+			"enabled":  cty.Bool,
+			"backends": cty.List(cty.String),
+		}), nil, false).
+		AddVariable("konnect_token", "string", nil, true).
+		AddVariableValidation("mesh_name", "length(var.mesh_name) > 0", "The mesh name must not be empty.").
+		SetLocal("mesh_name", hclbuilder.Func("lower", hclbuilder.Ref("var.mesh_name"))).
+		SetLocal("zone_count", hclbuilder.Func("length", hclbuilder.Ref("var.zones")))
+	builder.SetBlock("resource.konnect_mesh_control_plane.cp1", map[string]any{
+		"name": hclbuilder.Ref("local.mesh_name"),
+	})
+	builder.AddOutput("control_plane_id", builder.Block("resource.konnect_mesh_control_plane.cp1").Ref("id"))
+	builder.Block("output.control_plane_id").AddAttribute("description", `"ID of the control plane"`)
+
+	// Replacing a variable keeps its position
+	builder.AddVariable("zones", "set(string)", []string{"eu"}, false)
+	builder.SetLocal("zone_count", 2)
+
+	result, err := builder.BuildE()
+	require.NoError(t, err)
+	goldenFile := filepath.Join("testdata", "variables-locals-outputs.golden.tf")
+	assertGoldenFile(t, goldenFile, result)
+}
+
+// Test SetLocal() - values are set in the locals block defining them
+func TestSetLocal(t *testing.T) {
+	builder, err := hclbuilder.FromString(`
+locals {
+  mesh_name = "default"
+}
+
+locals {
+  region = "eu-west-1"
+}
+`)
+	require.NoError(t, err)
+
+	builder.SetLocal("region", "eu-central-1").SetLocal("zone", "eu")
+	value, ok := builder.GetAttribute("locals.zone")
+	require.True(t, ok)
+	require.Equal(t, "eu", value)
+
+	blocks := builder.Blocks("locals")
+	require.Len(t, blocks, 2)
+	require.Equal(t, `
+locals {
+  mesh_name = "default"
+  zone      = "eu"
+}
+
+locals {
+  region = "eu-central-1"
+}
+`, builder.Build())
+}
+
+// Test AddVariable() - invalid names and type constraints
+func TestAddVariable_Errors(t *testing.T) {
+	builder := hclbuilder.New().AddVariable("zones", "list(strin)", nil, false)
+	requirePathError(t, builder.Err(), "AddVariable", "variable.zones", hclbuilder.ErrInvalidExpression)
+
+	builder = hclbuilder.New().AddVariable("zones", 42, nil, false)
+	requirePathError(t, builder.Err(), "AddVariable", "variable.zones", hclbuilder.ErrUnsupportedValue)
+
+	builder = hclbuilder.New().AddVariable("mesh name", "string", nil, false)
+	requirePathError(t, builder.Err(), "AddVariable", `variable."mesh name"`, hclbuilder.ErrInvalidPath)
+
+	builder = hclbuilder.New().AddVariableValidation("missing", "true", "message")
+	requirePathError(t, builder.Err(), "AddVariableValidation", "variable.missing", hclbuilder.ErrBlockNotFound)
+
+	builder = hclbuilder.New().AddVariable("zones", "any", nil, false).AddVariableValidation("zones", "length(", "message")
+	requirePathError(t, builder.Err(), "AddVariableValidation", "variable.zones", hclbuilder.ErrInvalidExpression)
+	require.NotContains(t, builder.Build(), "validation")
+}